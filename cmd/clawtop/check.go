@@ -0,0 +1,81 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/cl4wb0rg/clawtop/internal/check"
+	"github.com/cl4wb0rg/clawtop/internal/collect"
+	"github.com/cl4wb0rg/clawtop/internal/config"
+	"github.com/cl4wb0rg/clawtop/internal/host"
+)
+
+// runCheck implements "clawtop check": the rules in the config's "checks"
+// evaluated once, printed as one Nagios plugin line, with the state as
+// the exit code. Anything that stops the check from running is UNKNOWN
+// (3), never the 2 other commands use for usage errors, which a monitor
+// would read as CRITICAL.
+func runCheck(args []string) int {
+	fs := flag.NewFlagSet("check", flag.ContinueOnError)
+	sf := addSettingsFlags(fs)
+	cronError := fs.Bool("cron-error", true, "critical when an enabled cron job's last run failed")
+	overdue := fs.String("overdue", "", "WARN,BAD: how late past its next run an enabled cron job may be (default 15m,1h)")
+	stale := fs.String("stale", "", "WARN,BAD: how long sessions.json may go unmodified (default 30m,2h)")
+	runtime := fs.String("runtime", "", "WARN,BAD: how long a subagent may be running (default 30m,2h)")
+	disk := fs.String("disk", "", "WARN,BAD: percent used of the filesystem holding the OpenClaw root (default 85,95)")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: clawtop check [--stale 30m,2h] [--overdue 15m,1h] [--runtime 30m,2h] [--disk 85,95] [flags]\n\nExits 0 (OK), 1 (WARNING), 2 (CRITICAL) or 3 (UNKNOWN). An empty WARN or BAD turns that level off.\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	unknown := func(err error) int {
+		fmt.Println("CLAWTOP UNKNOWN - " + err.Error())
+		return int(check.Unknown)
+	}
+	// even --help is UNKNOWN: a monitor must not read it as healthy
+	if err := fs.Parse(args); err != nil {
+		return unknown(err)
+	}
+
+	file, err := sf.load()
+	if err != nil {
+		return unknown(err)
+	}
+	cfg, err := sf.resolveProfile(file, sf.profileName(file))
+	if err != nil {
+		return unknown(err)
+	}
+	var ferr error
+	fs.Visit(func(fl *flag.Flag) {
+		var err error
+		switch fl.Name {
+		case "cron-error":
+			cfg.Checks.CronError = *cronError
+		case "overdue":
+			cfg.Checks.CronOverdue, err = config.ParseDurationThreshold(*overdue)
+		case "stale":
+			cfg.Checks.SessionsStale, err = config.ParseDurationThreshold(*stale)
+		case "runtime":
+			cfg.Checks.SubagentRuntime, err = config.ParseDurationThreshold(*runtime)
+		case "disk":
+			cfg.Checks.Disk, err = config.ParseThreshold(*disk)
+		}
+		if err != nil && ferr == nil {
+			ferr = fmt.Errorf("--%s: %w", fl.Name, err)
+		}
+	})
+	if ferr == nil {
+		ferr = cfg.Validate()
+	}
+	if ferr != nil {
+		return unknown(ferr)
+	}
+	watched, err := sf.watched(file, cfg)
+	if err != nil {
+		return unknown(err)
+	}
+
+	r := check.Run(time.Now(), cfg.Checks, collect.ReadAll(watched, cfg.Limits), host.ReadDisk)
+	fmt.Println(r)
+	return int(r.State())
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/cl4wb0rg/clawtop/internal/collect"
+	"github.com/cl4wb0rg/clawtop/internal/config"
+	"github.com/cl4wb0rg/clawtop/internal/list"
+	"github.com/cl4wb0rg/clawtop/internal/openclaw"
+	"github.com/cl4wb0rg/clawtop/internal/query"
+)
+
+// runList implements "clawtop sessions|subagents|crons|tasks": one dataset,
+// filtered like the UI's panel, in a pipeable format.
+func runList(dataset string, args []string) int {
+	fs := flag.NewFlagSet(dataset, flag.ExitOnError)
+	sf := addSettingsFlags(fs)
+	format := fs.String("format", "table", "output format: "+strings.Join(list.Formats, ", "))
+	columns := fs.String("columns", "", "comma-separated columns to print (default: a readable subset)")
+	sortBy := fs.String("sort", "", "column to sort by, prefixed with - for descending (default: newest first: updatedAt, createdAt, lastRunAt or at)")
+	limit := fs.Int("limit", 0, "print at most this many rows (default: all)")
+	all := fs.Bool("all", false, "ignore the config's filter toggles (--filter still applies)")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: clawtop %s [--format table|csv|tsv|json] [--columns a,b] [--sort [-]col] [--limit N] [flags]\n\nFlags:\n", dataset)
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	file, err := sf.load()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	cfg, err := sf.resolveProfile(file, sf.profileName(file))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	watched, err := sf.watched(file, cfg)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	search, err := query.Parse(cfg.Filter)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	ds := collect.ReadAll(watched, cfg.Limits)
+	data := collect.Merge(ds, cfg.Limits)
+	if data.Err != nil {
+		fmt.Fprintln(os.Stderr, data.Err)
+		return 1
+	}
+	for _, d := range ds {
+		if d.Err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", d.Instance, d.Err)
+		}
+	}
+
+	toggles := togglesOf(cfg.Filters, data.Sessions)
+	now := time.Now()
+	multi := len(cfg.Instances) > 0
+	var t *list.Table
+	switch dataset {
+	case "sessions":
+		f := search
+		if !*all {
+			f = toggles.Sessions().And(search)
+		}
+		t = list.Sessions(filter(data.Sessions, f, query.SessionRecord, now), multi)
+	case "subagents":
+		t = list.Subagents(filter(data.Subagents, search, query.SubagentRecord, now), multi, now)
+	case "crons":
+		t = list.Crons(filter(data.Crons, search, query.CronRecord, now), multi)
+	case "tasks":
+		f := search
+		if !*all {
+			f = toggles.Tasks().And(search)
+		}
+		t = list.Tasks(filter(data.Tasks, f, query.TaskRecord, now), multi)
+	}
+	if err := t.Sort(*sortBy); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	if err := t.Select(config.SplitList(*columns)); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	t.Limit(*limit)
+	if err := t.Write(os.Stdout, *format); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	return 0
+}
+
+// togglesOf is the UI's filter toggles as the config starts them.
+func togglesOf(f config.Filters, sessions []openclaw.Session) query.Toggles {
+	t := query.Toggles{Window: f.Window, Recent: f.Recent, HideRunSessions: f.HideRunSessions}
+	if f.PrimaryModelOnly {
+		t.PrimaryModel = openclaw.PrimaryModel(sessions)
+	}
+	for _, l := range []openclaw.TaskLevel{openclaw.LevelError, openclaw.LevelWarn, openclaw.LevelInfo, openclaw.LevelDebug} {
+		if f.Levels[string(l)] {
+			t.Levels = append(t.Levels, string(l))
+		}
+	}
+	for _, s := range []openclaw.TaskSource{openclaw.SourceCron, openclaw.SourceSubagent, openclaw.SourceTool} {
+		if f.Sources[string(s)] {
+			t.Sources = append(t.Sources, string(s))
+		}
+	}
+	return t
+}
+
+func filter[T any](items []T, f query.Filter, rec func(T) query.Record, now time.Time) []T {
+	var out []T
+	for _, it := range items {
+		if f.Match(rec(it), now) {
+			out = append(out, it)
+		}
+	}
+	return out
+}
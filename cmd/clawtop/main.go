@@ -1,35 +1,201 @@
 package main
 
 import (
+	"encoding/json"
 	"flag"
 	"fmt"
 	"os"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 
-	"github.com/cl4wb0rg/clawtop/internal/openclaw"
+	"github.com/cl4wb0rg/clawtop/internal/collect"
+	"github.com/cl4wb0rg/clawtop/internal/config"
+	"github.com/cl4wb0rg/clawtop/internal/list"
+	"github.com/cl4wb0rg/clawtop/internal/state"
+	"github.com/cl4wb0rg/clawtop/internal/statsd"
 	"github.com/cl4wb0rg/clawtop/internal/ui"
 )
 
 func main() {
-	var (
-		openclawRoot = flag.String("openclaw-root", "", "OpenClaw root dir (default: ~/.openclaw or $OPENCLAW_ROOT)")
-		workspace    = flag.String("workspace", "", "Workspace dir (default: <openclaw-root>/workspace)")
-		refresh      = flag.Duration("refresh", 2*time.Second, "refresh interval")
-	)
-	flag.Parse()
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "config":
+			os.Exit(runConfig(os.Args[2:]))
+		case "snapshot":
+			os.Exit(runSnapshot(os.Args[2:]))
+		case "serve":
+			os.Exit(runServe(os.Args[2:]))
+		case "traces":
+			os.Exit(runTraces(os.Args[2:]))
+		case "check":
+			os.Exit(runCheck(os.Args[2:]))
+		}
+		if slices.Contains(list.Datasets, os.Args[1]) {
+			os.Exit(runList(os.Args[1], os.Args[2:]))
+		}
+	}
+	os.Exit(runTop(os.Args[1:]))
+}
+
+// runTop is the interactive UI.
+func runTop(args []string) int {
+	fs := flag.NewFlagSet("clawtop", flag.ExitOnError)
+	sf := addSettingsFlags(fs)
+	resetUI := fs.Bool("reset-ui", false, "ignore the UI state saved by the last run (filters, view, sort, searches)")
+	once := fs.Bool("once", false, "print one plain-text snapshot and exit (same as -b -n 1)")
+	batch := fs.Bool("b", false, "batch mode: print plain-text snapshots to stdout instead of starting the UI")
+	iterations := fs.Int("n", 0, "with -b, exit after this many snapshots (default: run until interrupted)")
+	delay := fs.Duration("d", 0, "with -b, delay between snapshots (default: the refresh interval, else 2s)")
+	width := fs.Int("w", 0, "with -b, output width in columns (default: $COLUMNS, else 120)")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: clawtop [flags]\n       clawtop -b [-n N] [-d 5s] [flags]\n       clawtop snapshot [--json | --ndjson] [flags]\n       clawtop %s [--format table|csv|tsv|json] [flags]\n       clawtop serve [--listen 127.0.0.1:9469] [flags]\n       clawtop traces [--endpoint URL] [--since 24h] [--follow] [flags]\n       clawtop check [--stale 30m,2h] [--disk 85,95] [flags]\n       clawtop config show [flags]\n\nFlags:\n", strings.Join(list.Datasets, "|"))
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if *iterations < 0 || *delay < 0 {
+		fmt.Fprintln(os.Stderr, "-n and -d must not be negative")
+		fs.Usage()
+		return 2
+	}
 
-	paths, err := openclaw.DiscoverPaths(*openclawRoot, *workspace)
+	file, err := sf.load()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	cfg, err := sf.resolveProfile(file, sf.profileName(file))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	watched, err := sf.watched(file, cfg)
 	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
-		os.Exit(2)
+		return 2
 	}
 
-	m := ui.New(ui.Config{Paths: paths, Refresh: *refresh})
-	p := tea.NewProgram(m, tea.WithAltScreen())
-	if _, err := p.Run(); err != nil {
+	exp, err := statsd.New(cfg.StatsD)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	defer exp.Close()
+
+	if *once || *batch {
+		n := *iterations
+		if *once {
+			n = 1
+		}
+		d := *delay
+		if d == 0 {
+			d = cfg.Refresh.D()
+		}
+		if d == 0 {
+			d = 2 * time.Second
+		}
+		return runBatch(cfg, watched, exp, n, d, *width)
+	}
+
+	statePath := state.DefaultPath()
+	var saved *state.State
+	if !*resetUI && statePath != "" {
+		if saved, err = state.Load(statePath); err != nil {
+			// a broken state file only costs the saved settings
+			fmt.Fprintln(os.Stderr, "ignoring UI state:", err)
+		}
+	}
+	uc := uiConfig(cfg, watched[0].Paths)
+	uc.State = saved
+	uc.OnRefresh = pushTo(exp)
+	if len(cfg.Instances) > 0 {
+		uc.Instances = watched
+	} else {
+		uc.Profiles = sf.uiProfiles(file, cfg.Profile)
+	}
+	m, err := ui.New(uc)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	opts := []tea.ProgramOption{tea.WithAltScreen()}
+	if cfg.Mouse {
+		opts = append(opts, tea.WithMouseCellMotion())
+	}
+	p := tea.NewProgram(m, opts...)
+	final, err := p.Run()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if st, ok := ui.StateOf(final); ok && statePath != "" {
+		if err := state.Save(statePath, st); err != nil {
+			fmt.Fprintln(os.Stderr, "saving UI state:", err)
+		}
+	}
+	return 0
+}
+
+// runBatch prints n snapshots (0: forever) d apart. Unlike the UI it
+// ignores the saved UI state, so its output depends only on the config
+// and flags.
+func runBatch(cfg config.Config, watched []collect.Instance, exp *statsd.Exporter, n int, d time.Duration, w int) int {
+	if w <= 0 {
+		w, _ = strconv.Atoi(os.Getenv("COLUMNS"))
+	}
+	if w <= 0 {
+		w = 120
+	}
+	uc := uiConfig(cfg, watched[0].Paths)
+	uc.OnRefresh = pushTo(exp)
+	if len(cfg.Instances) > 0 {
+		uc.Instances = watched
+	}
+	b, err := ui.NewBatch(uc, w)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	for i := 0; n == 0 || i < n; i++ {
+		if i > 0 {
+			time.Sleep(d)
+			fmt.Println()
+		}
+		fmt.Println(b.Next())
+	}
+	return 0
+}
+
+// runConfig implements "clawtop config show".
+func runConfig(args []string) int {
+	if len(args) == 0 || args[0] != "show" {
+		fmt.Fprintln(os.Stderr, "usage: clawtop config show [flags]")
+		return 2
+	}
+	fs := flag.NewFlagSet("config show", flag.ExitOnError)
+	sf := addSettingsFlags(fs)
+	fs.Parse(args[1:])
+
+	cfg, err := sf.resolve()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	if cfg.Theme == "" {
+		cfg.Theme = ui.DefaultTheme
+	}
+	// show where discovery lands, not just what was asked for
+	if paths, err := collect.Paths(cfg); err == nil {
+		cfg.OpenClawRoot, cfg.Workspace = paths.OpenClawRoot, paths.WorkspaceDir
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(cfg.Redacted()); err != nil {
 		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+		return 1
 	}
+	return 0
 }
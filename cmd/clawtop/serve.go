@@ -0,0 +1,79 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/cl4wb0rg/clawtop/internal/collect"
+	"github.com/cl4wb0rg/clawtop/internal/host"
+	"github.com/cl4wb0rg/clawtop/internal/prom"
+	"github.com/cl4wb0rg/clawtop/internal/statsd"
+)
+
+// runServe implements "clawtop serve": a Prometheus /metrics endpoint that
+// reads the installations afresh on every scrape.
+func runServe(args []string) int {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	sf := addSettingsFlags(fs)
+	listen := fs.String("listen", "127.0.0.1:9469", "address to serve /metrics on")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: clawtop serve [--listen 127.0.0.1:9469] [flags]\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	file, err := sf.load()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	cfg, err := sf.resolveProfile(file, sf.profileName(file))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	watched, err := sf.watched(file, cfg)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	exp, err := statsd.New(cfg.StatsD)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	defer exp.Close()
+
+	// scrapes take turns so each CPU rate covers the time since the last one
+	var mu sync.Mutex
+	sampler := collect.Sampler{SysRoot: cfg.SysfsRoot}
+	sampler.Sample(time.Now())
+	read := func(now time.Time) (host.HostMetrics, []collect.Data) {
+		mu.Lock()
+		defer mu.Unlock()
+		h, ds := sampler.Sample(now), collect.ReadAll(watched, cfg.Limits)
+		exp.Push(h, ds)
+		return h, ds
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", prom.Handler(read))
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprintln(w, "clawtop: metrics are at /metrics")
+	})
+	fmt.Fprintf(os.Stderr, "clawtop: serving metrics on http://%s/metrics\n", *listen)
+	if err := http.ListenAndServe(*listen, mux); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
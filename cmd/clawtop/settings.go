@@ -0,0 +1,182 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/cl4wb0rg/clawtop/internal/collect"
+	"github.com/cl4wb0rg/clawtop/internal/config"
+	"github.com/cl4wb0rg/clawtop/internal/host"
+	"github.com/cl4wb0rg/clawtop/internal/openclaw"
+	"github.com/cl4wb0rg/clawtop/internal/statsd"
+	"github.com/cl4wb0rg/clawtop/internal/ui"
+)
+
+// settingsFlags are the flags that override config file settings. Every
+// command that reads OpenClaw state registers them.
+type settingsFlags struct {
+	fs           *flag.FlagSet
+	configPath   *string
+	profile      *string
+	instances    *string
+	statsd       *string
+	openclawRoot *string
+	workspace    *string
+	sysRoot      *string
+	refresh      *time.Duration
+	filter       *string
+	theme        *string
+	mouse        *bool
+}
+
+func addSettingsFlags(fs *flag.FlagSet) *settingsFlags {
+	return &settingsFlags{
+		fs:           fs,
+		configPath:   fs.String("config", "", "config file (default: $CLAWTOP_CONFIG or $XDG_CONFIG_HOME/clawtop/config.json)"),
+		profile:      fs.String("profile", "", "config profile to start with (default: $CLAWTOP_PROFILE or the config's \"profile\")"),
+		instances:    fs.String("instances", "", "comma-separated profiles to watch together (default: $CLAWTOP_INSTANCES or the config's \"instances\")"),
+		statsd:       fs.String("statsd", "", "push metrics to this StatsD/DogStatsD host:port after every refresh (default: $CLAWTOP_STATSD or the config's statsd.addr)"),
+		openclawRoot: fs.String("openclaw-root", "", "OpenClaw root dir (default: ~/.openclaw or $OPENCLAW_ROOT)"),
+		workspace:    fs.String("workspace", "", "Workspace dir (default: <openclaw-root>/workspace)"),
+		sysRoot:      fs.String("sysfs-root", "", "sysfs mount for temperature and CPU frequency sensors (default: /sys)"),
+		refresh:      fs.Duration("refresh", 0, "refresh interval (default: the last run's, else 2s)"),
+		filter:       fs.String("filter", "", "filter expression, e.g. 'level:error source:cron since:6h'"),
+		theme:        fs.String("theme", "", "colour theme: "+strings.Join(ui.ThemeNames(), ", ")+" (default: "+ui.DefaultTheme+", or mono when NO_COLOR is set)"),
+		mouse:        fs.Bool("mouse", false, "enable mouse: click panels, rows, tabs and filter toggles, wheel to scroll (disables terminal text selection)"),
+	}
+}
+
+// load reads the config file named by --config or $CLAWTOP_CONFIG.
+func (f *settingsFlags) load() (config.Config, error) {
+	path := *f.configPath
+	if path == "" {
+		path = os.Getenv("CLAWTOP_CONFIG")
+	}
+	return config.Load(path)
+}
+
+// profileName is the profile to start with: --profile, $CLAWTOP_PROFILE,
+// then the config's own choice.
+func (f *settingsFlags) profileName(file config.Config) string {
+	for _, n := range []string{*f.profile, os.Getenv("CLAWTOP_PROFILE")} {
+		if n != "" {
+			return n
+		}
+	}
+	return file.Profile
+}
+
+// resolve builds the effective config for the starting profile.
+func (f *settingsFlags) resolve() (config.Config, error) {
+	file, err := f.load()
+	if err != nil {
+		return config.Config{}, err
+	}
+	return f.resolveProfile(file, f.profileName(file))
+}
+
+// resolveProfile builds the effective config for one profile of file:
+// flags over environment over the profile over the config file over the
+// defaults.
+func (f *settingsFlags) resolveProfile(file config.Config, name string) (config.Config, error) {
+	cfg, err := file.WithProfile(name)
+	if err != nil {
+		return config.Config{}, err
+	}
+	if err := cfg.ApplyEnv(os.Getenv); err != nil {
+		return config.Config{}, err
+	}
+	f.fs.Visit(func(fl *flag.Flag) {
+		switch fl.Name {
+		case "openclaw-root":
+			cfg.OpenClawRoot = *f.openclawRoot
+		case "workspace":
+			cfg.Workspace = *f.workspace
+		case "sysfs-root":
+			cfg.SysfsRoot = *f.sysRoot
+		case "refresh":
+			cfg.Refresh = config.Duration(*f.refresh)
+		case "filter":
+			cfg.Filter = *f.filter
+		case "theme":
+			cfg.Theme = *f.theme
+		case "mouse":
+			cfg.Mouse = *f.mouse
+		case "instances":
+			cfg.Instances = config.SplitList(*f.instances)
+		case "statsd":
+			cfg.StatsD.Addr = *f.statsd
+		}
+	})
+	cfg.OpenClawRoot = config.ExpandHome(cfg.OpenClawRoot)
+	cfg.Workspace = config.ExpandHome(cfg.Workspace)
+	cfg.Tokens = config.ExpandHome(cfg.Tokens)
+	cfg.SysfsRoot = config.ExpandHome(cfg.SysfsRoot)
+	return cfg, cfg.Validate()
+}
+
+// watched resolves what cfg reads: each of cfg.Instances, from file's
+// profiles alone (see collect.Instances), or else cfg's own paths, which
+// must exist, under the active profile's name as in the UI.
+func (f *settingsFlags) watched(file, cfg config.Config) ([]collect.Instance, error) {
+	if len(cfg.Instances) == 0 {
+		paths, err := collect.Paths(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return []collect.Instance{{Name: cfg.Profile, Paths: paths}}, nil
+	}
+	return collect.Instances(file, cfg.Instances), nil
+}
+
+// uiProfiles resolves every profile for switching inside the UI, with the
+// top-level config first (as "default") when no profile is selected. A
+// profile whose root is missing is kept, with its error, so switching to
+// it says why it is empty.
+func (f *settingsFlags) uiProfiles(file config.Config, active string) []ui.Profile {
+	if len(file.Profiles) == 0 {
+		return nil
+	}
+	names := file.ProfileNames()
+	if active == "" {
+		names = append([]string{""}, names...)
+	}
+	out := make([]ui.Profile, 0, len(names))
+	for _, n := range names {
+		p := ui.Profile{Name: n}
+		cfg, err := f.resolveProfile(file, n)
+		if err == nil {
+			p.Paths, err = collect.Paths(cfg)
+			p.Filter, p.Filters = cfg.Filter, cfg.Filters
+		}
+		p.Err = err
+		out = append(out, p)
+	}
+	return out
+}
+
+// pushTo is a ui.Config.OnRefresh that pushes to e; nil without one.
+func pushTo(e *statsd.Exporter) func(host.HostMetrics, []collect.Data) {
+	if e == nil {
+		return nil
+	}
+	return func(h host.HostMetrics, ds []collect.Data) { e.Push(h, ds) }
+}
+
+// uiConfig is the ui.Config for cfg.
+func uiConfig(cfg config.Config, paths openclaw.Paths) ui.Config {
+	return ui.Config{
+		Paths:      paths,
+		Refresh:    cfg.Refresh.D(),
+		SysRoot:    cfg.SysfsRoot,
+		Filter:     cfg.Filter,
+		Keys:       cfg.Keys,
+		Theme:      cfg.Theme,
+		Filters:    cfg.Filters,
+		Limits:     cfg.Limits,
+		Thresholds: cfg.Thresholds,
+		Profile:    cfg.Profile,
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/cl4wb0rg/clawtop/internal/collect"
+	"github.com/cl4wb0rg/clawtop/internal/query"
+	"github.com/cl4wb0rg/clawtop/internal/snapshot"
+	"github.com/cl4wb0rg/clawtop/internal/statsd"
+)
+
+// runSnapshot implements "clawtop snapshot": the normalised model as JSON
+// (see schema/snapshot.v1.json), once or as a stream of lines.
+func runSnapshot(args []string) int {
+	fs := flag.NewFlagSet("snapshot", flag.ExitOnError)
+	sf := addSettingsFlags(fs)
+	fs.Bool("json", true, "print one indented JSON snapshot (the default)")
+	ndjson := fs.Bool("ndjson", false, "print a compact snapshot per line every --interval until interrupted")
+	interval := fs.Duration("interval", 0, "with --ndjson, time between snapshots (default: the refresh interval, else 2s)")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: clawtop snapshot [--json | --ndjson [--interval 10s]] [flags]\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	file, err := sf.load()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	cfg, err := sf.resolveProfile(file, sf.profileName(file))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	watched, err := sf.watched(file, cfg)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	f, err := query.Parse(cfg.Filter)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	d := *interval
+	if d == 0 {
+		d = cfg.Refresh.D()
+	}
+	if d == 0 {
+		d = 2 * time.Second
+	}
+
+	exp, err := statsd.New(cfg.StatsD)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	defer exp.Close()
+
+	sampler := collect.Sampler{SysRoot: cfg.SysfsRoot}
+	sampler.Prime()
+	enc := json.NewEncoder(os.Stdout)
+	if !*ndjson {
+		enc.SetIndent("", "  ")
+	}
+	for i := 0; ; i++ {
+		if i > 0 {
+			time.Sleep(d)
+		}
+		at := time.Now()
+		h := sampler.Sample(at)
+		ds := collect.ReadAll(watched, cfg.Limits)
+		exp.Push(h, ds)
+		s := snapshot.Build(at, h, ds, cfg.Limits, f)
+		if err := enc.Encode(s); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		if !*ndjson {
+			return 0
+		}
+	}
+}
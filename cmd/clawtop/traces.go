@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/cl4wb0rg/clawtop/internal/collect"
+	"github.com/cl4wb0rg/clawtop/internal/otlp"
+)
+
+// followOverlap is how far back each --follow poll looks past the last
+// export, for runs whose files were written late; the runs already sent
+// are skipped.
+const followOverlap = time.Minute
+
+// runTraces implements "clawtop traces": finished subagent and cron runs,
+// with their tool calls, sent to an OTLP/HTTP collector as spans.
+func runTraces(args []string) int {
+	fs := flag.NewFlagSet("traces", flag.ExitOnError)
+	sf := addSettingsFlags(fs)
+	endpoint := fs.String("endpoint", "", "OTLP/HTTP collector URL (default: $OTEL_EXPORTER_OTLP_ENDPOINT, the config's otlp.endpoint, else http://127.0.0.1:4318)")
+	since := fs.Duration("since", 24*time.Hour, "export runs that finished within this long")
+	follow := fs.Bool("follow", false, "keep running and export runs as they finish, every --refresh")
+	printOnly := fs.Bool("print", false, "print the OTLP/JSON request to stdout instead of sending it")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: clawtop traces [--endpoint http://127.0.0.1:4318] [--since 24h] [--follow] [--print] [flags]\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	file, err := sf.load()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	cfg, err := sf.resolveProfile(file, sf.profileName(file))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	if *endpoint != "" {
+		cfg.OTLP.Endpoint = *endpoint
+	}
+	if err := cfg.OTLP.Validate(); err != nil && !*printOnly {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	watched, err := sf.watched(file, cfg)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	d := cfg.Refresh.D()
+	if d == 0 {
+		d = 2 * time.Second
+	}
+
+	exp := otlp.New(cfg.OTLP)
+	from := time.Now().Add(-*since)
+	// sent holds the runs already exported, by trace, with when they ended
+	sent := map[[16]byte]time.Time{}
+	for i := 0; ; i++ {
+		if i > 0 {
+			time.Sleep(d)
+		}
+		now := time.Now()
+		var spans []otlp.Span
+		for j, data := range collect.ReadAll(watched, cfg.Limits) {
+			for _, sp := range otlp.Runs(watched[j], data, from) {
+				if _, ok := sent[sp.TraceID]; !ok {
+					spans = append(spans, sp)
+				}
+			}
+		}
+		if *printOnly {
+			// following, only polls that found runs print
+			if len(spans) > 0 || !*follow {
+				b, err := otlp.Encode(spans)
+				if err != nil {
+					fmt.Fprintln(os.Stderr, err)
+					return 1
+				}
+				fmt.Println(string(b))
+			}
+		} else if err := exp.Export(context.Background(), spans); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			if !*follow {
+				return 1
+			}
+			// try these runs again next time
+			continue
+		}
+		if !*follow {
+			return 0
+		}
+		from = now.Add(-followOverlap)
+		for _, sp := range spans {
+			if sp.ParentID == ([8]byte{}) {
+				sent[sp.TraceID] = sp.End
+			}
+		}
+		for id, end := range sent {
+			if !end.After(from) {
+				delete(sent, id)
+			}
+		}
+	}
+}
@@ -0,0 +1,260 @@
+// Package check evaluates health rules over what clawtop reads, for
+// "clawtop check": a Nagios-style state, a one-line summary with perfdata
+// and the matching exit code.
+package check
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cl4wb0rg/clawtop/internal/collect"
+	"github.com/cl4wb0rg/clawtop/internal/config"
+	"github.com/cl4wb0rg/clawtop/internal/host"
+)
+
+// State is a Nagios plugin state; its value is the exit code.
+type State int
+
+const (
+	OK State = iota
+	Warning
+	Critical
+	Unknown
+)
+
+func (s State) String() string {
+	switch s {
+	case OK:
+		return "OK"
+	case Warning:
+		return "WARNING"
+	case Critical:
+		return "CRITICAL"
+	}
+	return "UNKNOWN"
+}
+
+// rank orders states by urgency: a critical problem outweighs not
+// knowing about another.
+func (s State) rank() int {
+	return [...]int{OK: 0, Warning: 1, Unknown: 2, Critical: 3}[s]
+}
+
+// Problem is one rule that isn't met.
+type Problem struct {
+	State State
+	Text  string
+}
+
+// Perf is one perfdata value. Zero Warn, Bad and Max are left out.
+type Perf struct {
+	Label     string
+	Value     float64
+	UOM       string
+	Warn, Bad float64
+	Max       float64
+	// BadZero writes a crit of 0, for counts where any at all is critical.
+	BadZero bool
+}
+
+type Report struct {
+	// Instances and Crons count what was checked, for the OK summary.
+	Instances, Crons int
+	Problems         []Problem
+	Perf             []Perf
+}
+
+// State is the most urgent problem's state, or OK.
+func (r Report) State() State {
+	s := OK
+	for _, p := range r.Problems {
+		if p.State.rank() > s.rank() {
+			s = p.State
+		}
+	}
+	return s
+}
+
+// String is the plugin output: "CLAWTOP <STATE> - <summary> | <perfdata>".
+func (r Report) String() string {
+	summary := fmt.Sprintf("%d instance%s, %d cron%s checked", r.Instances, plural(r.Instances), r.Crons, plural(r.Crons))
+	if len(r.Problems) > 0 {
+		ps := append([]Problem(nil), r.Problems...)
+		sort.SliceStable(ps, func(i, j int) bool { return ps[i].State.rank() > ps[j].State.rank() })
+		texts := make([]string, len(ps))
+		for i, p := range ps {
+			texts[i] = p.Text
+		}
+		summary = strings.Join(texts, "; ")
+	}
+	perf := make([]string, len(r.Perf))
+	for i, p := range r.Perf {
+		perf[i] = p.String()
+	}
+	out := "CLAWTOP " + r.State().String() + " - " + summary
+	if len(perf) > 0 {
+		out += " | " + strings.Join(perf, " ")
+	}
+	return out
+}
+
+// String formats p as 'label'=value[UOM];[warn];[crit];0[;max].
+func (p Perf) String() string {
+	label := p.Label
+	if strings.ContainsAny(label, " '=") {
+		label = "'" + strings.ReplaceAll(label, "'", "''") + "'"
+	}
+	bad := optNum(p.Bad)
+	if p.BadZero {
+		bad = "0"
+	}
+	s := label + "=" + num(p.Value) + p.UOM + ";" + optNum(p.Warn) + ";" + bad + ";0"
+	if p.Max != 0 {
+		s += ";" + num(p.Max)
+	}
+	return s
+}
+
+func num(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+func optNum(v float64) string {
+	if v == 0 {
+		return ""
+	}
+	return num(v)
+}
+
+func plural(n int) string {
+	if n == 1 {
+		return ""
+	}
+	return "s"
+}
+
+// level grades v: Critical from bad, Warning from warn; a zero level is
+// off.
+func level(v, warn, bad float64) State {
+	switch {
+	case bad > 0 && v >= bad:
+		return Critical
+	case warn > 0 && v >= warn:
+		return Warning
+	}
+	return OK
+}
+
+func durLevel(d time.Duration, t config.DurationThreshold) State {
+	return level(d.Seconds(), t.Warn.D().Seconds(), t.Bad.D().Seconds())
+}
+
+// short formats d to the minute, or the second under a minute.
+func short(d time.Duration) string {
+	switch {
+	case d >= time.Hour:
+		return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+	case d >= time.Minute:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	}
+	return fmt.Sprintf("%ds", int(d.Seconds()))
+}
+
+func firstLine(s string) string {
+	s, _, _ = strings.Cut(strings.TrimSpace(s), "\n")
+	if r := []rune(s); len(r) > 80 {
+		s = string(r[:79]) + "…"
+	}
+	return s
+}
+
+// Run checks every instance in ds at now. disk reads the usage of the
+// filesystem holding a path (host.ReadDisk outside tests).
+func Run(now time.Time, c config.Checks, ds []collect.Data, disk func(path string) (host.Disk, error)) Report {
+	var r Report
+	for _, d := range ds {
+		r.Instances++
+		// with several instances, texts and labels say which one
+		name, label := "", ""
+		if len(ds) > 1 {
+			name, label = d.Instance+": ", d.Instance+"_"
+		}
+		add := func(s State, format string, args ...any) {
+			if s != OK {
+				r.Problems = append(r.Problems, Problem{s, name + fmt.Sprintf(format, args...)})
+			}
+		}
+		if d.Err != nil {
+			add(Unknown, "%v", d.Err)
+			continue
+		}
+
+		if src, ok := d.Source("sessions"); ok && !src.ModTime.IsZero() {
+			age := now.Sub(src.ModTime)
+			add(durLevel(age, c.SessionsStale), "sessions.json not updated for %s", short(age))
+			r.Perf = append(r.Perf, Perf{Label: label + "sessions_age", Value: age.Round(time.Second).Seconds(), UOM: "s", Warn: c.SessionsStale.Warn.D().Seconds(), Bad: c.SessionsStale.Bad.D().Seconds()})
+		}
+
+		failed := 0
+		var maxLate time.Duration
+		for _, j := range d.Crons {
+			if !j.Enabled {
+				continue
+			}
+			r.Crons++
+			if j.LastStatus == "error" {
+				failed++
+				if c.CronError {
+					msg := "cron " + j.Name + " failed"
+					if e := firstLine(j.LastError); e != "" {
+						msg += ": " + e
+					}
+					add(Critical, "%s", msg)
+				}
+			}
+			if j.NextRun != nil && now.After(*j.NextRun) {
+				late := now.Sub(*j.NextRun)
+				maxLate = max(maxLate, late)
+				add(durLevel(late, c.CronOverdue), "cron %s overdue by %s", j.Name, short(late))
+			}
+		}
+		// a single failed job is critical, so crit is 0: above it is bad
+		r.Perf = append(r.Perf,
+			Perf{Label: label + "crons_failed", Value: float64(failed), BadZero: c.CronError},
+			Perf{Label: label + "cron_max_late", Value: maxLate.Round(time.Second).Seconds(), UOM: "s", Warn: c.CronOverdue.Warn.D().Seconds(), Bad: c.CronOverdue.Bad.D().Seconds()})
+
+		running := 0
+		var longest time.Duration
+		for _, s := range d.Subagents {
+			if s.Status() != "running" {
+				continue
+			}
+			running++
+			rt := now.Sub(*s.StartedAt)
+			longest = max(longest, rt)
+			who := s.Label
+			if who == "" {
+				who = s.RunID
+			}
+			add(durLevel(rt, c.SubagentRuntime), "subagent %s running for %s", who, short(rt))
+		}
+		r.Perf = append(r.Perf,
+			Perf{Label: label + "subagents_running", Value: float64(running)},
+			Perf{Label: label + "subagent_max_runtime", Value: longest.Round(time.Second).Seconds(), UOM: "s", Warn: c.SubagentRuntime.Warn.D().Seconds(), Bad: c.SubagentRuntime.Bad.D().Seconds()})
+
+		if d.Root != "" && (c.Disk.Warn > 0 || c.Disk.Bad > 0) {
+			du, err := disk(d.Root)
+			if err != nil {
+				add(Unknown, "disk: %v", err)
+				continue
+			}
+			pct := du.UsedPercent()
+			add(level(pct, c.Disk.Warn, c.Disk.Bad), "disk %.0f%% used at %s", pct, d.Root)
+			r.Perf = append(r.Perf, Perf{Label: label + "disk_used", Value: float64(int(pct*10)) / 10, UOM: "%", Warn: c.Disk.Warn, Bad: c.Disk.Bad, Max: 100})
+		}
+	}
+	return r
+}
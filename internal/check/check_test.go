@@ -0,0 +1,105 @@
+package check
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cl4wb0rg/clawtop/internal/collect"
+	"github.com/cl4wb0rg/clawtop/internal/config"
+	"github.com/cl4wb0rg/clawtop/internal/host"
+	"github.com/cl4wb0rg/clawtop/internal/openclaw"
+)
+
+var now = time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+
+func ago(d time.Duration) *time.Time {
+	t := now.Add(-d)
+	return &t
+}
+
+func healthy() collect.Data {
+	return collect.Data{
+		Root:      "/srv/oc",
+		Crons:     []openclaw.CronJob{{Name: "nightly", Enabled: true, NextRun: ago(-time.Hour), LastStatus: "ok"}},
+		Subagents: []openclaw.SubagentRun{{RunID: "r1", Label: "research", StartedAt: ago(5 * time.Minute)}},
+		Sources:   []collect.Source{{Name: "sessions", ModTime: now.Add(-time.Minute)}},
+	}
+}
+
+func diskAt(pct float64) func(string) (host.Disk, error) {
+	return func(string) (host.Disk, error) {
+		return host.Disk{TotalBytes: 1000, UsedBytes: uint64(pct * 10), AvailBytes: 1000 - uint64(pct*10)}, nil
+	}
+}
+
+func TestRunOK(t *testing.T) {
+	r := Run(now, config.Default().Checks, []collect.Data{healthy()}, diskAt(40))
+	if r.State() != OK {
+		t.Fatalf("%s", r)
+	}
+	want := "CLAWTOP OK - 1 instance, 1 cron checked | sessions_age=60s;1800;7200;0 crons_failed=0;;0;0 cron_max_late=0s;900;3600;0 subagents_running=1;;;0 subagent_max_runtime=300s;1800;7200;0 disk_used=40%;85;95;0;100"
+	if got := r.String(); got != want {
+		t.Fatalf("got  %s\nwant %s", got, want)
+	}
+}
+
+func TestRunProblems(t *testing.T) {
+	c := config.Default().Checks
+	for _, tc := range []struct {
+		name  string
+		edit  func(*collect.Data)
+		disk  float64
+		state State
+		text  string
+	}{
+		{"cron error", func(d *collect.Data) { d.Crons[0].LastStatus, d.Crons[0].LastError = "error", "timeout\nstack" }, 40, Critical, "cron nightly failed: timeout"},
+		{"disabled cron ignored", func(d *collect.Data) { d.Crons[0].LastStatus, d.Crons[0].Enabled = "error", false }, 40, OK, ""},
+		{"overdue", func(d *collect.Data) { d.Crons[0].NextRun = ago(20 * time.Minute) }, 40, Warning, "cron nightly overdue by 20m"},
+		{"stale", func(d *collect.Data) { d.Sources[0].ModTime = now.Add(-3 * time.Hour) }, 40, Critical, "sessions.json not updated for 3h00m"},
+		{"long subagent", func(d *collect.Data) { d.Subagents[0].StartedAt = ago(45 * time.Minute) }, 40, Warning, "subagent research running for 45m"},
+		{"disk", func(d *collect.Data) {}, 96, Critical, "disk 96% used at /srv/oc"},
+		{"unreadable", func(d *collect.Data) { d.Err = errors.New("openclaw root not found") }, 40, Unknown, "openclaw root not found"},
+	} {
+		d := healthy()
+		tc.edit(&d)
+		r := Run(now, c, []collect.Data{d}, diskAt(tc.disk))
+		if r.State() != tc.state || !strings.Contains(r.String(), " - "+tc.text) {
+			t.Fatalf("%s: %s", tc.name, r)
+		}
+	}
+}
+
+func TestRunInstances(t *testing.T) {
+	prod, staging := healthy(), healthy()
+	prod.Instance, staging.Instance = "prod", "staging"
+	prod.Crons[0].LastStatus = "error"
+	staging.Subagents[0].StartedAt = ago(time.Hour)
+	disk := func(string) (host.Disk, error) {
+		return host.Disk{}, errors.New("statfs failed")
+	}
+	r := Run(now, config.Default().Checks, []collect.Data{staging, prod, {Instance: "gone", Err: errors.New("not found")}}, disk)
+	if r.State() != Critical {
+		t.Fatalf("%s", r)
+	}
+	s := r.String()
+	// the critical problem comes first, then unknown, then warning
+	if !strings.HasPrefix(s, "CLAWTOP CRITICAL - prod: cron nightly failed; staging: disk: statfs failed;") || !strings.Contains(s, "staging: subagent research running for 1h00m") {
+		t.Fatalf("%s", s)
+	}
+	if !strings.Contains(s, "prod_crons_failed=1;;0;0") {
+		t.Fatalf("labels: %s", s)
+	}
+	// one instance is named after its profile, but labels stay the same
+	// whichever profile is active
+	if s := Run(now, config.Default().Checks, []collect.Data{prod}, disk).String(); strings.Contains(s, "prod") {
+		t.Fatalf("single instance prefixed: %s", s)
+	}
+}
+
+func TestPerfLabel(t *testing.T) {
+	if got := (Perf{Label: "my prod_disk_used", Value: 1.5, UOM: "%"}).String(); got != "'my prod_disk_used'=1.5%;;;0" {
+		t.Fatal(got)
+	}
+}
@@ -0,0 +1,311 @@
+// Package collect reads everything clawtop shows about an OpenClaw
+// installation in one pass, and merges several installations into one
+// view.
+package collect
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cl4wb0rg/clawtop/internal/config"
+	"github.com/cl4wb0rg/clawtop/internal/openclaw"
+)
+
+// Instance is one installation to read. Err is why its paths could not be
+// resolved; Read then returns it instead of data.
+type Instance struct {
+	Name  string
+	Paths openclaw.Paths
+	Err   error
+}
+
+// DefaultInstance names the installation in exported data when no
+// profile is active: every command names one by its profile.
+const DefaultInstance = "default"
+
+// InstanceName is n, or DefaultInstance when n is empty.
+func InstanceName(n string) string {
+	if n == "" {
+		return DefaultInstance
+	}
+	return n
+}
+
+// Paths finds cfg's OpenClaw files, with cfg.Tokens, when set, in place
+// of the workspace's tokens file.
+func Paths(cfg config.Config) (openclaw.Paths, error) {
+	p, err := openclaw.DiscoverPaths(config.ExpandHome(cfg.OpenClawRoot), config.ExpandHome(cfg.Workspace))
+	if err == nil && cfg.Tokens != "" {
+		p.TokensJSONL = config.ExpandHome(cfg.Tokens)
+	}
+	return p, err
+}
+
+// Instances resolves the named profiles of file. Only file's own settings
+// are used: the environment and flags that pick one root would point every
+// instance at it. A profile that is unknown, or whose root is missing, is
+// kept with its error so it shows up as unhealthy.
+func Instances(file config.Config, names []string) []Instance {
+	out := make([]Instance, 0, len(names))
+	for _, n := range names {
+		in := Instance{Name: n}
+		cfg, err := file.WithProfile(n)
+		if err == nil {
+			in.Paths, err = Paths(cfg)
+		}
+		in.Err = err
+		out = append(out, in)
+	}
+	return out
+}
+
+// Data is what Read found for one instance, or Merge for several.
+type Data struct {
+	Instance string
+	// Root is the instance's OpenClaw root, when it was found.
+	Root         string
+	Err          error
+	Sessions     []openclaw.Session
+	Subagents    []openclaw.SubagentRun
+	Crons        []openclaw.CronJob
+	Tasks        []openclaw.Task
+	TokenSamples []openclaw.TokenSample
+	// Sources is the state of each file Read looked at.
+	Sources []Source
+}
+
+// Source is one file (or directory) an instance is read from.
+type Source struct {
+	// Name is sessions, subagents, crons, cronRuns, transcript or tokens.
+	Name    string
+	Path    string
+	ModTime time.Time
+	// Err is why it could not be read; optional files that do not exist
+	// have an Err satisfying os.IsNotExist.
+	Err error
+}
+
+// Status is "ok", "missing" or "error".
+func (s Source) Status() string {
+	switch {
+	case s.Err == nil:
+		return "ok"
+	case errors.Is(s.Err, fs.ErrNotExist):
+		return "missing"
+	}
+	return "error"
+}
+
+// Source returns the named source, if Read looked at it.
+func (d Data) Source(name string) (Source, bool) {
+	for _, s := range d.Sources {
+		if s.Name == name {
+			return s, true
+		}
+	}
+	return Source{}, false
+}
+
+func (d *Data) addSource(name, path string, err error) {
+	src := Source{Name: name, Path: path, Err: err}
+	if st, serr := os.Stat(path); serr == nil {
+		src.ModTime = st.ModTime()
+	} else if err == nil {
+		src.Err = serr
+	}
+	d.Sources = append(d.Sources, src)
+}
+
+// Read reads in's sessions, subagent runs, cron jobs, tasks and token
+// samples, capped by limits. Only sessions.json is required; the other
+// files are skipped when missing. Records are stamped with in.Name.
+func Read(in Instance, limits config.Limits) Data {
+	out := Data{Instance: in.Name, Root: in.Paths.OpenClawRoot}
+	if in.Err != nil {
+		out.Err = in.Err
+		return out
+	}
+	paths := in.Paths
+	sessions, err := openclaw.ReadSessionsJSON(paths.SessionsJSON)
+	out.addSource("sessions", paths.SessionsJSON, err)
+	if err != nil {
+		out.Err = err
+		return out
+	}
+	out.Sessions = sessions
+	sub, err := openclaw.ReadSubagentRuns(paths.SubagentRuns)
+	out.addSource("subagents", paths.SubagentRuns, err)
+	if err == nil {
+		out.Subagents = sub
+	}
+	cr, err := openclaw.ReadCronJobs(paths.CronJobs)
+	out.addSource("crons", paths.CronJobs, err)
+	if err == nil {
+		out.Crons = cr
+	}
+
+	// tasks: cron finished + tool results + subagent runs
+	tasks := make([]openclaw.Task, 0, 64)
+	st, err := os.Stat(paths.CronRunsDir)
+	if err == nil && !st.IsDir() {
+		err = fmt.Errorf("%s: not a directory", paths.CronRunsDir)
+	}
+	out.addSource("cronRuns", paths.CronRunsDir, err)
+	if err == nil {
+		for i, cj := range out.Crons {
+			p := openclaw.CronRunFile(paths.CronRunsDir, cj.ID)
+			if _, err := os.Stat(p); err == nil {
+				if t, ok, _ := openclaw.ReadLatestCronRun(p); ok {
+					t.Title = "cron: " + cj.Name
+					tasks = append(tasks, t)
+				}
+				out.Crons[i].ConsecutiveErrors, _ = openclaw.ReadCronFailStreak(p)
+			}
+		}
+	}
+	// heuristic: tool results come from the newest main session file
+	matches, _ := filepath.Glob(filepath.Join(paths.OpenClawRoot, "agents", "main", "sessions", "*.jsonl"))
+	sort.Slice(matches, func(i, j int) bool {
+		iSt, _ := os.Stat(matches[i])
+		jSt, _ := os.Stat(matches[j])
+		if iSt == nil || jSt == nil {
+			return matches[i] > matches[j]
+		}
+		return iSt.ModTime().After(jSt.ModTime())
+	})
+	if len(matches) > 0 {
+		toolTasks, err := openclaw.ReadToolTasks(matches[0], limits.ToolLines, limits.ToolTasks)
+		out.addSource("transcript", matches[0], err)
+		if err == nil {
+			tasks = append(tasks, toolTasks...)
+		}
+	}
+	for _, sa := range out.Subagents {
+		detail := strings.TrimSpace(sa.Task)
+		tasks = append(tasks, openclaw.Task{At: sa.CreatedAt, Level: openclaw.LevelInfo, Source: openclaw.SourceSubagent, Title: "subagent: " + sa.Label, Detail: detail})
+	}
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].At.After(tasks[j].At) })
+	if len(tasks) > limits.Tasks {
+		tasks = tasks[:limits.Tasks]
+	}
+	out.Tasks = tasks
+
+	// tokens optional
+	samples, err := openclaw.ReadTokenSamples(paths.TokensJSONL, limits.TokenSamples)
+	out.addSource("tokens", paths.TokensJSONL, err)
+	if err == nil {
+		out.TokenSamples = samples
+	}
+
+	if in.Name != "" {
+		out.stamp(in.Name)
+	}
+	return out
+}
+
+func (d *Data) stamp(name string) {
+	for i := range d.Sessions {
+		d.Sessions[i].Instance = name
+	}
+	for i := range d.Subagents {
+		d.Subagents[i].Instance = name
+	}
+	for i := range d.Crons {
+		d.Crons[i].Instance = name
+	}
+	for i := range d.Tasks {
+		d.Tasks[i].Instance = name
+	}
+}
+
+// ReadAll reads every instance in order.
+func ReadAll(ins []Instance, limits config.Limits) []Data {
+	out := make([]Data, len(ins))
+	for i, in := range ins {
+		out[i] = Read(in, limits)
+	}
+	return out
+}
+
+// Merge combines ds into one Data: records are concatenated (tasks newest
+// first, not re-capped) and token samples are summed with MergeTokens. Err
+// is set only when every instance failed, to the first one's error.
+func Merge(ds []Data, limits config.Limits) Data {
+	var out Data
+	series := make([][]openclaw.TokenSample, 0, len(ds))
+	ok := 0
+	for _, d := range ds {
+		if d.Err != nil {
+			if out.Err == nil {
+				out.Err = d.Err
+			}
+			continue
+		}
+		ok++
+		out.Sessions = append(out.Sessions, d.Sessions...)
+		out.Subagents = append(out.Subagents, d.Subagents...)
+		out.Crons = append(out.Crons, d.Crons...)
+		out.Tasks = append(out.Tasks, d.Tasks...)
+		if len(d.TokenSamples) > 0 {
+			series = append(series, d.TokenSamples)
+		}
+	}
+	if ok > 0 {
+		out.Err = nil
+	}
+	sort.SliceStable(out.Sessions, func(i, j int) bool { return out.Sessions[i].UpdatedAt.After(out.Sessions[j].UpdatedAt) })
+	sort.SliceStable(out.Tasks, func(i, j int) bool { return out.Tasks[i].At.After(out.Tasks[j].At) })
+	out.TokenSamples = MergeTokens(series, limits.TokenSamples)
+	return out
+}
+
+// MergeTokens sums token series sampled at different times into one: at
+// each sample time of any series, the total is the sum of every series'
+// latest sample at or before it. It starts once every series has a sample,
+// so the combined total doesn't jump when a late series first appears, and
+// keeps the newest n samples.
+func MergeTokens(series [][]openclaw.TokenSample, n int) []openclaw.TokenSample {
+	switch len(series) {
+	case 0:
+		return nil
+	case 1:
+		return series[0]
+	}
+	start := series[0][0].At
+	var times []openclaw.TokenSample
+	for _, s := range series {
+		if s[0].At.After(start) {
+			start = s[0].At
+		}
+		times = append(times, s...)
+	}
+	sort.SliceStable(times, func(i, j int) bool { return times[i].At.Before(times[j].At) })
+
+	next := make([]int, len(series))
+	var out []openclaw.TokenSample
+	for i, t := range times {
+		if t.At.Before(start) || (i+1 < len(times) && times[i+1].At.Equal(t.At)) {
+			continue
+		}
+		sum := openclaw.TokenSample{At: t.At}
+		for k, s := range series {
+			for next[k] < len(s) && !s[next[k]].At.After(t.At) {
+				next[k]++
+			}
+			last := s[next[k]-1]
+			sum.OpenClawTotal += last.OpenClawTotal
+			sum.ClaudeCostUSD += last.ClaudeCostUSD
+		}
+		out = append(out, sum)
+	}
+	if n > 0 && len(out) > n {
+		out = out[len(out)-n:]
+	}
+	return out
+}
@@ -0,0 +1,126 @@
+package collect
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cl4wb0rg/clawtop/internal/config"
+	"github.com/cl4wb0rg/clawtop/internal/openclaw"
+)
+
+func writeRoot(t *testing.T, sessions string) openclaw.Paths {
+	t.Helper()
+	root := t.TempDir()
+	dir := filepath.Join(root, "agents", "main", "sessions")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "sessions.json"), []byte(sessions), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	p, err := openclaw.DiscoverPaths(root, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestReadAllMerge(t *testing.T) {
+	limits := config.Default().Limits
+	prod := writeRoot(t, `{"agent:main:main": {"model":"gpt-5.2","updatedAt": 1700000000000}}`)
+	staging := writeRoot(t, `{"agent:main:main": {"model":"gpt-5.2","updatedAt": 1700000060000}}`)
+	ds := ReadAll([]Instance{
+		{Name: "prod", Paths: prod},
+		{Name: "staging", Paths: staging},
+		{Name: "gone", Err: errors.New("openclaw root not found")},
+	}, limits)
+	if ds[0].Err != nil || ds[1].Err != nil || ds[2].Err == nil {
+		t.Fatalf("errs: %v %v %v", ds[0].Err, ds[1].Err, ds[2].Err)
+	}
+	m := Merge(ds, limits)
+	if m.Err != nil || len(m.Sessions) != 2 {
+		t.Fatalf("err=%v sessions=%d", m.Err, len(m.Sessions))
+	}
+	if m.Sessions[0].Instance != "staging" || m.Sessions[1].Instance != "prod" {
+		t.Fatalf("want newest first and stamped, got %+v", m.Sessions)
+	}
+	if Merge(ds[2:], limits).Err == nil {
+		t.Fatal("want an error when every instance failed")
+	}
+	if d := Read(Instance{Paths: prod}, limits); d.Sessions[0].Instance != "" {
+		t.Fatalf("unnamed instance stamped %q", d.Sessions[0].Instance)
+	}
+}
+
+func TestInstances(t *testing.T) {
+	prod := writeRoot(t, `{}`)
+	staging := writeRoot(t, `{}`)
+	// a root picked for single-instance runs must not leak into instances
+	t.Setenv("OPENCLAW_ROOT", writeRoot(t, `{}`).OpenClawRoot)
+	file := config.Default()
+	file.Profiles = map[string]config.Profile{
+		"prod":    {OpenClawRoot: prod.OpenClawRoot},
+		"staging": {OpenClawRoot: staging.OpenClawRoot, Tokens: "/srv/staging/tokens.jsonl"},
+	}
+	ins := Instances(file, []string{"prod", "staging", "gone"})
+	if len(ins) != 3 || ins[0].Name != "prod" || ins[1].Name != "staging" {
+		t.Fatalf("%+v", ins)
+	}
+	if ins[0].Err != nil || ins[0].Paths.OpenClawRoot != prod.OpenClawRoot {
+		t.Fatalf("prod: %+v", ins[0])
+	}
+	if ins[1].Err != nil || ins[1].Paths.OpenClawRoot != staging.OpenClawRoot || ins[1].Paths.TokensJSONL != "/srv/staging/tokens.jsonl" {
+		t.Fatalf("staging: %+v", ins[1])
+	}
+	if ins[2].Err == nil {
+		t.Fatal("want an error for an unknown profile")
+	}
+}
+
+func TestMergeTokens(t *testing.T) {
+	at := func(min int) time.Time { return time.Date(2026, 1, 1, 0, min, 0, 0, time.UTC) }
+	a := []openclaw.TokenSample{{At: at(0), OpenClawTotal: 100}, {At: at(10), OpenClawTotal: 200, ClaudeCostUSD: 1}}
+	b := []openclaw.TokenSample{{At: at(5), OpenClawTotal: 10}, {At: at(10), OpenClawTotal: 20, ClaudeCostUSD: 0.5}, {At: at(15), OpenClawTotal: 30}}
+	got := MergeTokens([][]openclaw.TokenSample{a, b}, 0)
+	want := []int64{110, 220, 230} // at 5, 10 and 15; a's sample at 0 predates b
+	if len(got) != len(want) {
+		t.Fatalf("got %+v", got)
+	}
+	for i, w := range want {
+		if got[i].OpenClawTotal != w {
+			t.Fatalf("sample %d: got %d, want %d", i, got[i].OpenClawTotal, w)
+		}
+	}
+	if got[1].ClaudeCostUSD != 1.5 || !got[2].At.Equal(at(15)) {
+		t.Fatalf("got %+v", got[1:])
+	}
+	if got := MergeTokens([][]openclaw.TokenSample{a, b}, 1); len(got) != 1 || got[0].OpenClawTotal != 230 {
+		t.Fatalf("capped: %+v", got)
+	}
+}
+
+func TestReadHostThrottles(t *testing.T) {
+	sys := t.TempDir()
+	count := filepath.Join(sys, "devices", "system", "cpu", "cpu0", "thermal_throttle", "core_throttle_count")
+	if err := os.MkdirAll(filepath.Dir(count), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	at := time.Now()
+	var prev HostSample
+	// throttled 7 times since boot, then twice more, then not again
+	for i, tc := range []struct {
+		count    string
+		new, all uint64
+	}{{"7\n", 0, 7}, {"9\n", 2, 9}, {"9\n", 0, 9}} {
+		if err := os.WriteFile(count, []byte(tc.count), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		prev = ReadHost(sys, at.Add(time.Duration(i)*time.Second), prev)
+		if s := prev.Metrics.Sensors; s.NewThrottles != tc.new || s.ThrottleCount != tc.all {
+			t.Fatalf("sample %d: %+v", i, s)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package collect
+
+import (
+	"time"
+
+	"github.com/cl4wb0rg/clawtop/internal/host"
+)
+
+// HostSample is one host reading plus the raw counters the next reading
+// measures CPU, major fault and throttle rates against.
+type HostSample struct {
+	Metrics   host.HostMetrics
+	CPU       *host.CPUStat
+	VM        *host.VMStat
+	Throttles *uint64
+}
+
+// ReadHost reads the host metrics at at, with rates since prev (zero when
+// prev has no counters). Sensors are read from sysRoot.
+func ReadHost(sysRoot string, at time.Time, prev HostSample) HostSample {
+	var out HostSample
+	cpu, err := host.ReadCPUStat()
+	if err == nil {
+		out.CPU = &cpu
+	}
+	l1, l5, l15, _ := host.ReadLoadAvg()
+	total, avail, _ := host.ReadMemInfo()
+	used := total - avail
+	cpuPct := 0.0
+	if prev.CPU != nil && out.CPU != nil {
+		cpuPct = host.CPUPercent(*prev.CPU, cpu)
+	}
+	out.Metrics = host.HostMetrics{At: at, CPUPercent: cpuPct, MemUsedBytes: used, MemTotalBytes: total, Load1: l1, Load5: l5, Load15: l15}
+	if swapTotal, swapFree, err := host.ReadSwapInfo(); err == nil {
+		out.Metrics.SwapTotalBytes = swapTotal
+		out.Metrics.SwapUsedBytes = swapTotal - swapFree
+	}
+	if vm, err := host.ReadVMStat(); err == nil {
+		out.VM = &vm
+		if prev.VM != nil {
+			out.Metrics.MajFaultsPerSec = host.MajorFaultRate(*prev.VM, vm)
+		}
+	}
+	// PSI is all-or-nothing: if cpu is missing the kernel has it disabled.
+	if p, err := host.ReadPressure("cpu"); err == nil {
+		out.Metrics.HasPSI = true
+		out.Metrics.PSICPU = p
+		out.Metrics.PSIMemory, _ = host.ReadPressure("memory")
+		out.Metrics.PSIIO, _ = host.ReadPressure("io")
+	}
+	out.Metrics.Sensors, _ = host.ReadSensors(sysRoot)
+	n := out.Metrics.Sensors.ThrottleCount
+	out.Throttles = &n
+	if prev.Throttles != nil && n > *prev.Throttles {
+		out.Metrics.Sensors.NewThrottles = n - *prev.Throttles
+	}
+	return out
+}
+
+// CPUWindow is how long Prime waits after the first sample, so the next
+// one has CPU and fault rates instead of zeros.
+const CPUWindow = 500 * time.Millisecond
+
+// Sampler reads host metrics repeatedly, each with rates since the one
+// before. The first sample's rates are zero.
+type Sampler struct {
+	SysRoot string
+	prev    HostSample
+}
+
+// Sample reads the host metrics at at.
+func (s *Sampler) Sample(at time.Time) host.HostMetrics {
+	s.prev = ReadHost(s.SysRoot, at, s.prev)
+	return s.prev.Metrics
+}
+
+// Prime takes a first sample and waits CPUWindow, for one-shot output
+// that shouldn't show zero rates.
+func (s *Sampler) Prime() {
+	s.Sample(time.Now())
+	time.Sleep(CPUWindow)
+}
@@ -0,0 +1,528 @@
+// Package config loads clawtop's optional JSON config file.
+//
+// Settings come, highest first, from flags, environment variables, the
+// config file and the built-in defaults. Load and ApplyEnv cover the last
+// three; cmd/clawtop applies the flags.
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cl4wb0rg/clawtop/internal/host"
+	"github.com/cl4wb0rg/clawtop/internal/query"
+)
+
+type Config struct {
+	// OpenClawRoot and Workspace override path discovery; a leading ~/
+	// is expanded.
+	OpenClawRoot string `json:"openclawRoot,omitempty"`
+	Workspace    string `json:"workspace,omitempty"`
+	// Tokens overrides <workspace>/dashboard/metrics/tokens.jsonl, which
+	// carries the OpenClaw token totals and the Claude Code cost.
+	Tokens string `json:"tokens,omitempty"`
+	// SysfsRoot is where hwmon and cpufreq are read from.
+	SysfsRoot string `json:"sysfsRoot"`
+
+	// Refresh is the refresh interval. Zero means the last run's, else 2s.
+	Refresh Duration `json:"refresh,omitempty"`
+	// Filter is a filter expression applied to every panel at startup.
+	Filter string `json:"filter,omitempty"`
+	// Theme names the colour theme (dark, light, high-contrast,
+	// deuteranopia, mono).
+	Theme string `json:"theme,omitempty"`
+	Mouse bool   `json:"mouse,omitempty"`
+
+	// Keys rebinds UI actions by name (see the ? overlay or README):
+	// each entry replaces that action's default keys; an empty list
+	// unbinds it.
+	Keys map[string][]string `json:"keys,omitempty"`
+
+	Filters    Filters    `json:"filters"`
+	Limits     Limits     `json:"limits"`
+	Thresholds Thresholds `json:"thresholds"`
+
+	// Profile selects one of Profiles at startup.
+	Profile  string             `json:"profile,omitempty"`
+	Profiles map[string]Profile `json:"profiles,omitempty"`
+	// Instances names profiles that are watched together, on one screen,
+	// instead of one at a time.
+	Instances []string `json:"instances,omitempty"`
+
+	StatsD StatsD `json:"statsd"`
+	OTLP   OTLP   `json:"otlp"`
+	Checks Checks `json:"checks"`
+}
+
+// StatsD pushes metrics to a StatsD or DogStatsD agent over UDP after
+// every refresh, when Addr is set.
+type StatsD struct {
+	// Addr is the agent's host:port, e.g. 127.0.0.1:8125.
+	Addr string `json:"addr,omitempty"`
+	// Prefix starts every metric name.
+	Prefix string `json:"prefix"`
+	// Tags are added to every metric, as DogStatsD key:value tags.
+	Tags map[string]string `json:"tags,omitempty"`
+	// Plain leaves tags out, for agents that don't take DogStatsD tags.
+	Plain bool `json:"plain,omitempty"`
+}
+
+// OTLP is the collector "clawtop traces" sends spans to.
+type OTLP struct {
+	// Endpoint is the collector's OTLP/HTTP base URL; /v1/traces is
+	// appended to its path unless it already ends that way.
+	Endpoint string `json:"endpoint"`
+	// Headers are sent with every export, e.g. for authentication.
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+// Validate checks Endpoint. Config.Validate leaves it to "clawtop
+// traces", the only command that uses it, so an OTEL_EXPORTER_OTLP_ENDPOINT
+// set for other software can't stop the rest of clawtop from starting.
+func (o OTLP) Validate() error {
+	if u, err := url.Parse(o.Endpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("otlp.endpoint %q: want an http:// or https:// URL", o.Endpoint)
+	}
+	return nil
+}
+
+// Profile is a named OpenClaw installation. Its settings replace the
+// top-level ones when it is active; filters merge key by key.
+type Profile struct {
+	OpenClawRoot string          `json:"openclawRoot,omitempty"`
+	Workspace    string          `json:"workspace,omitempty"`
+	Tokens       string          `json:"tokens,omitempty"`
+	Filter       string          `json:"filter,omitempty"`
+	Filters      json.RawMessage `json:"filters,omitempty"`
+}
+
+// Filters are the filter toggles a run starts with (a saved UI state from
+// the last run takes precedence; see --reset-ui).
+type Filters struct {
+	// Window is how far back the recent-sessions toggle (1) reaches.
+	Window           string          `json:"window"`
+	Recent           bool            `json:"recent"`
+	HideRunSessions  bool            `json:"hideRunSessions"`
+	PrimaryModelOnly bool            `json:"primaryModelOnly"`
+	Levels           map[string]bool `json:"levels"`
+	Sources          map[string]bool `json:"sources"`
+}
+
+// Limits cap how much is read and shown.
+type Limits struct {
+	// Tasks is how many of the newest tasks are kept.
+	Tasks int `json:"tasks"`
+	// ToolLines is how many lines of the main session transcript are
+	// scanned for tool results, and ToolTasks how many are kept.
+	ToolLines int `json:"toolLines"`
+	ToolTasks int `json:"toolTasks"`
+	// TokenSamples is how many tokens.jsonl samples the sparkline spans.
+	TokenSamples int `json:"tokenSamples"`
+	// OverviewRows caps each Overview table; 0 means as many as fit.
+	OverviewRows PanelRows `json:"overviewRows"`
+}
+
+type PanelRows struct {
+	Sessions  int `json:"sessions"`
+	Subagents int `json:"subagents"`
+	Tasks     int `json:"tasks"`
+	Crons     int `json:"crons"`
+}
+
+// Threshold marks a value as a warning from Warn and bad from Bad.
+type Threshold struct {
+	Warn float64 `json:"warn"`
+	Bad  float64 `json:"bad"`
+}
+
+// Thresholds for the host panel. PSI values are percent of wall time
+// stalled, swap is percent used, major faults are per second and
+// temperatures are °C.
+type Thresholds struct {
+	PSISome     Threshold `json:"psiSome"`
+	PSIFull     Threshold `json:"psiFull"`
+	Swap        Threshold `json:"swap"`
+	MajorFaults Threshold `json:"majorFaults"`
+	Temp        Threshold `json:"temp"`
+}
+
+// WithDefaults returns t with each zero Threshold replaced by Default()'s,
+// since a zero one would grade every value bad.
+func (t Thresholds) WithDefaults() Thresholds {
+	def := Default().Thresholds
+	for _, p := range []struct {
+		t   *Threshold
+		def Threshold
+	}{
+		{&t.PSISome, def.PSISome},
+		{&t.PSIFull, def.PSIFull},
+		{&t.Swap, def.Swap},
+		{&t.MajorFaults, def.MajorFaults},
+		{&t.Temp, def.Temp},
+	} {
+		if *p.t == (Threshold{}) {
+			*p.t = p.def
+		}
+	}
+	return t
+}
+
+// DurationThreshold is a Threshold for durations.
+type DurationThreshold struct {
+	Warn Duration `json:"warn"`
+	Bad  Duration `json:"bad"`
+}
+
+// Checks are the rules "clawtop check" evaluates. A zero warn or bad
+// level turns that level of the rule off.
+type Checks struct {
+	// CronError makes an enabled cron job whose last run failed critical.
+	CronError bool `json:"cronError"`
+	// CronOverdue is how late past its next run an enabled job may be.
+	CronOverdue DurationThreshold `json:"cronOverdue"`
+	// SessionsStale is how long sessions.json may go unmodified.
+	SessionsStale DurationThreshold `json:"sessionsStale"`
+	// SubagentRuntime is how long a subagent may be running.
+	SubagentRuntime DurationThreshold `json:"subagentRuntime"`
+	// Disk is percent used of the filesystem holding each OpenClaw root.
+	Disk Threshold `json:"disk"`
+}
+
+// ParseThreshold parses "WARN,BAD", e.g. "85,95"; either may be empty
+// for 0.
+func ParseThreshold(s string) (Threshold, error) {
+	w, b, _ := strings.Cut(s, ",")
+	var t Threshold
+	for _, f := range []struct {
+		s   string
+		dst *float64
+	}{{w, &t.Warn}, {b, &t.Bad}} {
+		if f.s = strings.TrimSpace(f.s); f.s == "" {
+			continue
+		}
+		v, err := strconv.ParseFloat(f.s, 64)
+		if err != nil {
+			return Threshold{}, fmt.Errorf("%q: want WARN,BAD numbers", s)
+		}
+		*f.dst = v
+	}
+	return t, nil
+}
+
+// ParseDurationThreshold parses "WARN,BAD", e.g. "30m,2h"; either may
+// be empty for 0.
+func ParseDurationThreshold(s string) (DurationThreshold, error) {
+	w, b, _ := strings.Cut(s, ",")
+	var t DurationThreshold
+	for _, f := range []struct {
+		s   string
+		dst *Duration
+	}{{w, &t.Warn}, {b, &t.Bad}} {
+		if f.s = strings.TrimSpace(f.s); f.s == "" {
+			continue
+		}
+		v, err := time.ParseDuration(f.s)
+		if err != nil {
+			return DurationThreshold{}, fmt.Errorf("%q: want WARN,BAD durations", s)
+		}
+		*f.dst = Duration(v)
+	}
+	return t, nil
+}
+
+// Default is the configuration with no file, environment or flags.
+func Default() Config {
+	return Config{
+		SysfsRoot: host.DefaultSysRoot,
+		Filters: Filters{
+			Window:           "24h",
+			Recent:           true,
+			HideRunSessions:  true,
+			PrimaryModelOnly: true,
+			Levels:           map[string]bool{"error": true, "warn": true, "info": true, "debug": false},
+			// tool tasks can be very noisy; default off
+			Sources: map[string]bool{"cron": true, "subagent": true, "tool": false},
+		},
+		Limits: Limits{Tasks: 40, ToolLines: 400, ToolTasks: 25, TokenSamples: 48},
+		Thresholds: Thresholds{
+			PSISome:     Threshold{10, 40},
+			PSIFull:     Threshold{5, 20},
+			Swap:        Threshold{50, 80},
+			MajorFaults: Threshold{100, 1000},
+			Temp:        Threshold{75, 90},
+		},
+		StatsD: StatsD{Prefix: "clawtop."},
+		OTLP:   OTLP{Endpoint: "http://127.0.0.1:4318"},
+		Checks: Checks{
+			CronError:       true,
+			CronOverdue:     DurationThreshold{Duration(15 * time.Minute), Duration(time.Hour)},
+			SessionsStale:   DurationThreshold{Duration(30 * time.Minute), Duration(2 * time.Hour)},
+			SubagentRuntime: DurationThreshold{Duration(30 * time.Minute), Duration(2 * time.Hour)},
+			Disk:            Threshold{85, 95},
+		},
+	}
+}
+
+// DefaultPath is $XDG_CONFIG_HOME/clawtop/config.json, falling back to
+// ~/.config when XDG_CONFIG_HOME is unset.
+func DefaultPath() string {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		h, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(h, ".config")
+	}
+	return filepath.Join(dir, "clawtop", "config.json")
+}
+
+// Load reads the config at path over the defaults. If path is empty the
+// default location is used, and a missing default file yields Default().
+func Load(path string) (Config, error) {
+	c := Default()
+	explicit := path != ""
+	if !explicit {
+		path = DefaultPath()
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		if !explicit && os.IsNotExist(err) {
+			return c, nil
+		}
+		return Config{}, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&c); err != nil {
+		return Config{}, fmt.Errorf("%s: %w", path, err)
+	}
+	if err := c.Validate(); err != nil {
+		return Config{}, fmt.Errorf("%s: %w", path, err)
+	}
+	for _, n := range c.ProfileNames() {
+		if _, err := c.WithProfile(n); err != nil {
+			return Config{}, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	if c.Profile != "" {
+		if _, ok := c.Profiles[c.Profile]; !ok {
+			return Config{}, fmt.Errorf("%s: unknown profile %q", path, c.Profile)
+		}
+	}
+	return c, nil
+}
+
+// Redacted returns c with the values of otlp.headers, which usually hold
+// credentials, replaced by "<redacted>", for showing to the user.
+func (c Config) Redacted() Config {
+	if len(c.OTLP.Headers) == 0 {
+		return c
+	}
+	h := make(map[string]string, len(c.OTLP.Headers))
+	for k := range c.OTLP.Headers {
+		h[k] = "<redacted>"
+	}
+	c.OTLP.Headers = h
+	return c
+}
+
+// ProfileNames lists the configured profiles, sorted.
+func (c Config) ProfileNames() []string {
+	out := make([]string, 0, len(c.Profiles))
+	for n := range c.Profiles {
+		out = append(out, n)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// WithProfile returns c with the named profile's settings applied. The
+// empty name is the top-level config itself.
+func (c Config) WithProfile(name string) (Config, error) {
+	if name == "" {
+		return c, nil
+	}
+	p, ok := c.Profiles[name]
+	if !ok {
+		return Config{}, fmt.Errorf("unknown profile %q (have %s)", name, strings.Join(c.ProfileNames(), ", "))
+	}
+	c.Profile = name
+	set := func(dst *string, v string) {
+		if v != "" {
+			*dst = v
+		}
+	}
+	set(&c.OpenClawRoot, p.OpenClawRoot)
+	set(&c.Workspace, p.Workspace)
+	set(&c.Tokens, p.Tokens)
+	set(&c.Filter, p.Filter)
+	if len(p.Filters) > 0 {
+		// decode over a copy so the top-level maps are left alone
+		f := c.Filters
+		f.Levels, f.Sources = copyMap(f.Levels), copyMap(f.Sources)
+		dec := json.NewDecoder(bytes.NewReader(p.Filters))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&f); err != nil {
+			return Config{}, fmt.Errorf("profiles.%s.filters: %w", name, err)
+		}
+		c.Filters = f
+	}
+	if err := c.Validate(); err != nil {
+		return Config{}, fmt.Errorf("profiles.%s: %w", name, err)
+	}
+	return c, nil
+}
+
+func copyMap(m map[string]bool) map[string]bool {
+	out := make(map[string]bool, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+	return out
+}
+
+// ApplyEnv overrides c from the environment. NO_COLOR selects the mono
+// theme unless CLAWTOP_THEME names one.
+func (c *Config) ApplyEnv(getenv func(string) string) error {
+	str := func(name string, dst *string) {
+		if v := getenv(name); v != "" {
+			*dst = v
+		}
+	}
+	str("OPENCLAW_ROOT", &c.OpenClawRoot)
+	str("CLAWTOP_WORKSPACE", &c.Workspace)
+	str("CLAWTOP_SYSFS_ROOT", &c.SysfsRoot)
+	str("CLAWTOP_FILTER", &c.Filter)
+	if getenv("NO_COLOR") != "" {
+		c.Theme = "mono"
+	}
+	str("CLAWTOP_THEME", &c.Theme)
+	if v := getenv("CLAWTOP_REFRESH"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return fmt.Errorf("CLAWTOP_REFRESH: %w", err)
+		}
+		c.Refresh = Duration(d)
+	}
+	str("CLAWTOP_STATSD", &c.StatsD.Addr)
+	str("OTEL_EXPORTER_OTLP_ENDPOINT", &c.OTLP.Endpoint)
+	if v := getenv("CLAWTOP_INSTANCES"); v != "" {
+		c.Instances = SplitList(v)
+	}
+	if v := getenv("CLAWTOP_MOUSE"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("CLAWTOP_MOUSE: %w", err)
+		}
+		c.Mouse = b
+	}
+	return c.Validate()
+}
+
+// Validate reports settings that cannot work.
+func (c Config) Validate() error {
+	if c.Refresh != 0 && c.Refresh.D() < 500*time.Millisecond {
+		return fmt.Errorf("refresh %s: must be at least 500ms", c.Refresh)
+	}
+	if _, err := query.Parse(c.Filter); err != nil {
+		return fmt.Errorf("filter: %w", err)
+	}
+	if _, err := query.ParseDuration(c.Filters.Window); err != nil {
+		return fmt.Errorf("filters.window: %w", err)
+	}
+	for name, n := range map[string]int{"tasks": c.Limits.Tasks, "toolLines": c.Limits.ToolLines, "toolTasks": c.Limits.ToolTasks, "tokenSamples": c.Limits.TokenSamples} {
+		if n <= 0 {
+			return fmt.Errorf("limits.%s: must be positive", name)
+		}
+	}
+	r := c.Limits.OverviewRows
+	if r.Sessions < 0 || r.Subagents < 0 || r.Tasks < 0 || r.Crons < 0 {
+		return fmt.Errorf("limits.overviewRows: must not be negative")
+	}
+	for name, t := range map[string]Threshold{"psiSome": c.Thresholds.PSISome, "psiFull": c.Thresholds.PSIFull, "swap": c.Thresholds.Swap, "majorFaults": c.Thresholds.MajorFaults, "temp": c.Thresholds.Temp} {
+		if t.Bad <= 0 {
+			return fmt.Errorf("thresholds.%s: bad %g must be above 0", name, t.Bad)
+		}
+		if t.Warn > t.Bad {
+			return fmt.Errorf("thresholds.%s: warn %g is above bad %g", name, t.Warn, t.Bad)
+		}
+	}
+	if c.StatsD.Addr != "" {
+		if _, _, err := net.SplitHostPort(c.StatsD.Addr); err != nil {
+			return fmt.Errorf("statsd.addr: %w", err)
+		}
+	}
+	ck := c.Checks
+	for name, t := range map[string]DurationThreshold{"cronOverdue": ck.CronOverdue, "sessionsStale": ck.SessionsStale, "subagentRuntime": ck.SubagentRuntime} {
+		if t.Warn < 0 || t.Bad < 0 || (t.Bad != 0 && t.Warn > t.Bad) {
+			return fmt.Errorf("checks.%s: warn %s and bad %s must not be negative, and warn not above bad", name, t.Warn, t.Bad)
+		}
+	}
+	if d := ck.Disk; d.Warn < 0 || d.Warn > 100 || d.Bad > 100 || (d.Bad != 0 && d.Warn > d.Bad) {
+		return fmt.Errorf("checks.disk: warn %g and bad %g must be percentages, warn not above bad", d.Warn, d.Bad)
+	}
+	seen := map[string]bool{}
+	for _, n := range c.Instances {
+		if _, ok := c.Profiles[n]; !ok {
+			return fmt.Errorf("instances: unknown profile %q", n)
+		}
+		if seen[n] {
+			return fmt.Errorf("instances: %q listed twice", n)
+		}
+		seen[n] = true
+	}
+	return nil
+}
+
+// SplitList splits a comma-separated list, dropping empty items.
+func SplitList(s string) []string {
+	var out []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// ExpandHome replaces a leading ~/ in p with the home directory.
+func ExpandHome(p string) string {
+	if rest, ok := strings.CutPrefix(p, "~/"); ok {
+		if h, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(h, rest)
+		}
+	}
+	return p
+}
+
+// Duration is a time.Duration written as a string ("2s") in JSON.
+type Duration time.Duration
+
+func (d Duration) D() time.Duration { return time.Duration(d) }
+
+func (d Duration) String() string { return time.Duration(d).String() }
+
+func (d Duration) MarshalJSON() ([]byte, error) { return json.Marshal(d.String()) }
+
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
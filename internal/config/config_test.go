@@ -0,0 +1,226 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLoad_DefaultMissing(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	c, err := Load("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Keys != nil {
+		t.Fatalf("expected zero config: %#v", c)
+	}
+}
+
+func TestLoad_ExplicitMissing(t *testing.T) {
+	if _, err := Load(filepath.Join(t.TempDir(), "nope.json")); err == nil {
+		t.Fatal("expected error for missing explicit config")
+	}
+}
+
+func TestLoad_Keys(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	p := filepath.Join(dir, "clawtop", "config.json")
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(p, []byte(`{"keys": {"level_debug": ["D"], "source_tool": []}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	c, err := Load("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := c.Keys["level_debug"]; len(got) != 1 || got[0] != "D" {
+		t.Fatalf("level_debug=%v", got)
+	}
+	if got, ok := c.Keys["source_tool"]; !ok || len(got) != 0 {
+		t.Fatalf("source_tool=%v ok=%v", got, ok)
+	}
+}
+
+func TestLoad_UnknownField(t *testing.T) {
+	p := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(p, []byte(`{"keyz": {}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(p); err == nil {
+		t.Fatal("expected error for unknown field")
+	}
+}
+
+func TestLoad_OverDefaults(t *testing.T) {
+	p := filepath.Join(t.TempDir(), "config.json")
+	body := `{"refresh": "5s", "filters": {"levels": {"debug": true}}, "limits": {"tasks": 80}, "thresholds": {"temp": {"warn": 60, "bad": 70}}}`
+	if err := os.WriteFile(p, []byte(body), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	c, err := Load(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	def := Default()
+	if c.Refresh.String() != "5s" || c.Limits.Tasks != 80 || c.Limits.ToolLines != def.Limits.ToolLines {
+		t.Fatalf("refresh=%s limits=%+v", c.Refresh, c.Limits)
+	}
+	// maps merge key by key
+	if !c.Filters.Levels["debug"] || !c.Filters.Levels["error"] || c.Filters.Window != "24h" {
+		t.Fatalf("filters=%+v", c.Filters)
+	}
+	if c.Thresholds.Temp != (Threshold{60, 70}) || c.Thresholds.Swap != def.Thresholds.Swap {
+		t.Fatalf("thresholds=%+v", c.Thresholds)
+	}
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	for _, body := range []string{
+		`{"refresh": "10ms"}`,
+		`{"refresh": "soon"}`,
+		`{"filter": "\"open"}`,
+		`{"filters": {"window": "yesterday"}}`,
+		`{"limits": {"tasks": 0}}`,
+		`{"thresholds": {"swap": {"warn": 90, "bad": 80}}}`,
+		`{"thresholds": {"swap": {"warn": 0, "bad": 0}}}`,
+		`{"statsd": {"addr": "localhost"}}`,
+		`{"checks": {"sessionsStale": {"warn": "2h", "bad": "30m"}}}`,
+		`{"checks": {"disk": {"warn": 90, "bad": 120}}}`,
+		`{"checks": {"disk": {"warn": 120, "bad": 0}}}`,
+	} {
+		p := filepath.Join(t.TempDir(), "config.json")
+		if err := os.WriteFile(p, []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Load(p); err == nil {
+			t.Fatalf("%s: expected error", body)
+		}
+	}
+}
+
+func TestOTLPValidate(t *testing.T) {
+	// only "clawtop traces" checks the endpoint
+	p := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(p, []byte(`{"otlp": {"endpoint": "localhost:4318"}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	c, err := Load(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.OTLP.Validate(); err == nil {
+		t.Fatal("expected error for a URL without a scheme")
+	}
+	for _, ok := range []string{"http://localhost:4318", "https://gw.example.com/otlp"} {
+		if err := (OTLP{Endpoint: ok}).Validate(); err != nil {
+			t.Fatalf("%s: %v", ok, err)
+		}
+	}
+	if err := (OTLP{}).Validate(); err == nil {
+		t.Fatal("expected error for an empty endpoint")
+	}
+}
+
+func TestThresholdsWithDefaults(t *testing.T) {
+	def := Default().Thresholds
+	got := Thresholds{Swap: Threshold{Warn: 10, Bad: 20}}.WithDefaults()
+	if got.Swap != (Threshold{Warn: 10, Bad: 20}) || got.PSISome != def.PSISome || got.Temp != def.Temp {
+		t.Fatalf("%+v", got)
+	}
+}
+
+func TestRedacted(t *testing.T) {
+	c := Default()
+	c.OTLP.Headers = map[string]string{"Authorization": "Bearer secret"}
+	r := c.Redacted()
+	if r.OTLP.Headers["Authorization"] != "<redacted>" || c.OTLP.Headers["Authorization"] != "Bearer secret" {
+		t.Fatalf("redacted=%v original=%v", r.OTLP.Headers, c.OTLP.Headers)
+	}
+}
+
+func TestApplyEnv(t *testing.T) {
+	env := map[string]string{"CLAWTOP_REFRESH": "3s", "NO_COLOR": "1", "OPENCLAW_ROOT": "/srv/oc"}
+	c := Default()
+	c.Theme = "light"
+	c.OpenClawRoot = "/from/config"
+	if err := c.ApplyEnv(func(k string) string { return env[k] }); err != nil {
+		t.Fatal(err)
+	}
+	if c.Refresh.String() != "3s" || c.Theme != "mono" || c.OpenClawRoot != "/srv/oc" {
+		t.Fatalf("%+v", c)
+	}
+	env["CLAWTOP_THEME"] = "deuteranopia"
+	if err := c.ApplyEnv(func(k string) string { return env[k] }); err != nil || c.Theme != "deuteranopia" {
+		t.Fatalf("theme=%s err=%v", c.Theme, err)
+	}
+	env["CLAWTOP_MOUSE"] = "maybe"
+	if err := c.ApplyEnv(func(k string) string { return env[k] }); err == nil {
+		t.Fatal("expected error for CLAWTOP_MOUSE")
+	}
+}
+
+func TestWithProfile(t *testing.T) {
+	p := filepath.Join(t.TempDir(), "config.json")
+	body := `{"openclawRoot": "/srv/prod", "filter": "level:error", "profiles": {
+		"staging": {"openclawRoot": "/srv/staging", "tokens": "/srv/staging/tokens.jsonl", "filters": {"levels": {"debug": true}}}}}`
+	if err := os.WriteFile(p, []byte(body), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	c, err := Load(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := c.WithProfile("staging")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Profile != "staging" || s.OpenClawRoot != "/srv/staging" || s.Tokens != "/srv/staging/tokens.jsonl" || s.Filter != "level:error" {
+		t.Fatalf("%+v", s)
+	}
+	if !s.Filters.Levels["debug"] || !s.Filters.Levels["error"] || c.Filters.Levels["debug"] {
+		t.Fatalf("levels: profile=%v top=%v", s.Filters.Levels, c.Filters.Levels)
+	}
+	if _, err := c.WithProfile("prod"); err == nil {
+		t.Fatal("expected error for unknown profile")
+	}
+}
+
+func TestInstances(t *testing.T) {
+	c := Default()
+	c.Profiles = map[string]Profile{"prod": {}, "staging": {}}
+	env := map[string]string{"CLAWTOP_INSTANCES": "prod, staging"}
+	if err := c.ApplyEnv(func(k string) string { return env[k] }); err != nil || len(c.Instances) != 2 || c.Instances[1] != "staging" {
+		t.Fatalf("instances=%q err=%v", c.Instances, err)
+	}
+	c.Instances = []string{"prod", "dev"}
+	if err := c.Validate(); err == nil {
+		t.Fatal("expected error for unknown instance")
+	}
+	c.Instances = []string{"prod", "prod"}
+	if err := c.Validate(); err == nil {
+		t.Fatal("expected error for duplicate instance")
+	}
+}
+
+func TestParseThreshold(t *testing.T) {
+	if th, err := ParseThreshold("85, 95"); err != nil || th != (Threshold{85, 95}) {
+		t.Fatalf("%+v %v", th, err)
+	}
+	if th, err := ParseThreshold(",90"); err != nil || th != (Threshold{0, 90}) {
+		t.Fatalf("%+v %v", th, err)
+	}
+	if _, err := ParseThreshold("lots"); err == nil {
+		t.Fatal("expected error")
+	}
+	if th, err := ParseDurationThreshold("30m,2h"); err != nil || th.Warn.D() != 30*time.Minute || th.Bad.D() != 2*time.Hour {
+		t.Fatalf("%+v %v", th, err)
+	}
+	if _, err := ParseDurationThreshold("30,2h"); err == nil {
+		t.Fatal("expected error")
+	}
+}
@@ -0,0 +1,30 @@
+package host
+
+import "syscall"
+
+// Disk is the usage of the filesystem holding a path.
+type Disk struct {
+	TotalBytes uint64
+	// AvailBytes is what unprivileged users may still write; the blocks
+	// reserved for root count as used, as in df.
+	AvailBytes uint64
+	UsedBytes  uint64
+}
+
+// UsedPercent is used space as df reports it: used / (used + available).
+func (d Disk) UsedPercent() float64 {
+	if d.UsedBytes+d.AvailBytes == 0 {
+		return 0
+	}
+	return float64(d.UsedBytes) / float64(d.UsedBytes+d.AvailBytes) * 100
+}
+
+// ReadDisk reads the usage of the filesystem holding path.
+func ReadDisk(path string) (Disk, error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return Disk{}, err
+	}
+	bs := uint64(st.Bsize)
+	return Disk{TotalBytes: st.Blocks * bs, AvailBytes: st.Bavail * bs, UsedBytes: (st.Blocks - st.Bfree) * bs}, nil
+}
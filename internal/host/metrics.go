@@ -21,6 +21,18 @@ type HostMetrics struct {
 	Load1 float64
 	Load5 float64
 	Load15 float64
+
+	SwapUsedBytes uint64
+	SwapTotalBytes uint64
+	MajFaultsPerSec float64
+
+	// HasPSI is false on kernels without /proc/pressure (pre-4.20 or psi=0).
+	HasPSI bool
+	PSICPU Pressure
+	PSIMemory Pressure
+	PSIIO Pressure
+
+	Sensors Sensors
 }
 
 func ReadCPUStat() (CPUStat, error) {
@@ -61,28 +73,48 @@ func CPUPercent(prev, cur CPUStat) float64 {
 }
 
 func ReadMemInfo() (total, available uint64, err error) {
-	b, err := os.ReadFile("/proc/meminfo")
+	mi, err := readMemInfo()
+	if err != nil {
+		return 0, 0, err
+	}
+	return mi["MemTotal"], mi["MemAvailable"], nil
+}
+
+func ReadSwapInfo() (total, free uint64, err error) {
+	mi, err := readMemInfo()
 	if err != nil {
 		return 0, 0, err
 	}
-	var memTotal, memAvail uint64
-	for _, ln := range strings.Split(string(b), "\n") {
-		ln = strings.TrimSpace(ln)
-		if strings.HasPrefix(ln, "MemTotal:") {
-			f := strings.Fields(ln)
-			if len(f) >= 2 {
-				memTotal, _ = strconv.ParseUint(f[1], 10, 64)
-			}
+	return mi["SwapTotal"], mi["SwapFree"], nil
+}
+
+// readMemInfo returns /proc/meminfo as a map of field name to bytes.
+func readMemInfo() (map[string]uint64, error) {
+	b, err := os.ReadFile("/proc/meminfo")
+	if err != nil {
+		return nil, err
+	}
+	return parseMemInfo(string(b)), nil
+}
+
+func parseMemInfo(s string) map[string]uint64 {
+	out := map[string]uint64{}
+	for _, ln := range strings.Split(s, "\n") {
+		f := strings.Fields(ln)
+		if len(f) < 2 {
+			continue
+		}
+		v, err := strconv.ParseUint(f[1], 10, 64)
+		if err != nil {
+			continue
 		}
-		if strings.HasPrefix(ln, "MemAvailable:") {
-			f := strings.Fields(ln)
-			if len(f) >= 2 {
-				memAvail, _ = strconv.ParseUint(f[1], 10, 64)
-			}
+		// values are kB
+		if len(f) >= 3 && f[2] == "kB" {
+			v *= 1024
 		}
+		out[strings.TrimSuffix(f[0], ":")] = v
 	}
-	// values are kB
-	return memTotal * 1024, memAvail * 1024, nil
+	return out
 }
 
 func ReadLoadAvg() (l1, l5, l15 float64, err error) {
@@ -0,0 +1,34 @@
+package host
+
+import "testing"
+
+func TestParseMemInfo(t *testing.T) {
+	mi := parseMemInfo("MemTotal:       16314264 kB\n" +
+		"MemFree:         1234567 kB\n" +
+		"MemAvailable:    8157132 kB\n" +
+		"SwapTotal:       2097148 kB\n" +
+		"SwapFree:        1048574 kB\n" +
+		"HugePages_Total:       4\n" +
+		"Broken:             n/a kB\n" +
+		"Short:\n")
+	for _, tc := range []struct {
+		key  string
+		want uint64
+	}{
+		{"MemTotal", 16314264 * 1024},
+		{"MemAvailable", 8157132 * 1024},
+		{"SwapTotal", 2097148 * 1024},
+		{"SwapFree", 1048574 * 1024},
+		// counts have no unit and stay as they are
+		{"HugePages_Total", 4},
+	} {
+		if got := mi[tc.key]; got != tc.want {
+			t.Fatalf("%s: got %d, want %d", tc.key, got, tc.want)
+		}
+	}
+	for _, key := range []string{"Broken", "Short"} {
+		if _, ok := mi[key]; ok {
+			t.Fatalf("%s: unparseable line kept", key)
+		}
+	}
+}
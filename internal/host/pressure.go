@@ -0,0 +1,101 @@
+package host
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Pressure is one /proc/pressure/<resource> file: the share of wall time
+// in which some (or all) non-idle tasks were stalled on that resource.
+// Averages are percentages over 10s, 60s and 300s windows.
+type Pressure struct {
+	SomeAvg10, SomeAvg60, SomeAvg300 float64
+	FullAvg10, FullAvg60, FullAvg300 float64
+}
+
+// ReadPressure reads /proc/pressure/<resource> (cpu, memory or io).
+func ReadPressure(resource string) (Pressure, error) {
+	b, err := os.ReadFile("/proc/pressure/" + resource)
+	if err != nil {
+		return Pressure{}, err
+	}
+	return parsePressure(string(b))
+}
+
+func parsePressure(s string) (Pressure, error) {
+	var p Pressure
+	seen := false
+	for _, ln := range strings.Split(s, "\n") {
+		f := strings.Fields(ln)
+		if len(f) < 4 {
+			continue
+		}
+		// some avg10=0.00 avg60=0.00 avg300=0.00 total=0
+		var avg10, avg60, avg300 float64
+		for _, kv := range f[1:] {
+			k, v, ok := strings.Cut(kv, "=")
+			if !ok {
+				continue
+			}
+			x, _ := strconv.ParseFloat(v, 64)
+			switch k {
+			case "avg10":
+				avg10 = x
+			case "avg60":
+				avg60 = x
+			case "avg300":
+				avg300 = x
+			}
+		}
+		switch f[0] {
+		case "some":
+			p.SomeAvg10, p.SomeAvg60, p.SomeAvg300 = avg10, avg60, avg300
+			seen = true
+		case "full":
+			p.FullAvg10, p.FullAvg60, p.FullAvg300 = avg10, avg60, avg300
+			seen = true
+		}
+	}
+	if !seen {
+		return Pressure{}, fmt.Errorf("pressure: no some/full lines")
+	}
+	return p, nil
+}
+
+// VMStat holds the /proc/vmstat counters clawtop tracks.
+type VMStat struct {
+	At         time.Time
+	PgMajFault uint64
+}
+
+func ReadVMStat() (VMStat, error) {
+	b, err := os.ReadFile("/proc/vmstat")
+	if err != nil {
+		return VMStat{}, err
+	}
+	return parseVMStat(string(b), time.Now())
+}
+
+func parseVMStat(s string, at time.Time) (VMStat, error) {
+	vm := VMStat{At: at}
+	for _, ln := range strings.Split(s, "\n") {
+		f := strings.Fields(ln)
+		if len(f) == 2 && f[0] == "pgmajfault" {
+			vm.PgMajFault, _ = strconv.ParseUint(f[1], 10, 64)
+			return vm, nil
+		}
+	}
+	return VMStat{}, fmt.Errorf("/proc/vmstat: pgmajfault missing")
+}
+
+// MajorFaultRate returns major page faults per second between two samples.
+func MajorFaultRate(prev, cur VMStat) float64 {
+	dt := cur.At.Sub(prev.At).Seconds()
+	if dt <= 0 || cur.PgMajFault < prev.PgMajFault {
+		return 0
+	}
+	return float64(cur.PgMajFault-prev.PgMajFault) / dt
+}
@@ -0,0 +1,74 @@
+package host
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParsePressure(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		in   string
+		want Pressure
+		err  bool
+	}{
+		{
+			name: "memory",
+			in: "some avg10=1.50 avg60=0.75 avg300=0.20 total=123456\n" +
+				"full avg10=0.50 avg60=0.25 avg300=0.05 total=6543\n",
+			want: Pressure{SomeAvg10: 1.5, SomeAvg60: 0.75, SomeAvg300: 0.2, FullAvg10: 0.5, FullAvg60: 0.25, FullAvg300: 0.05},
+		},
+		{
+			// cpu has no full line before 5.13
+			name: "cpu without full",
+			in:   "some avg10=12.34 avg60=5.00 avg300=1.00 total=99\n",
+			want: Pressure{SomeAvg10: 12.34, SomeAvg60: 5, SomeAvg300: 1},
+		},
+		{
+			name: "unknown keys and short lines",
+			in:   "some avg10=2.00 avg60=1.00 avg300=0.50 total=1 extra=7\nfull\n\n",
+			want: Pressure{SomeAvg10: 2, SomeAvg60: 1, SomeAvg300: 0.5},
+		},
+		{name: "empty", in: "", err: true},
+		{name: "no some or full", in: "other avg10=1 avg60=1 avg300=1 total=1\n", err: true},
+	} {
+		got, err := parsePressure(tc.in)
+		if (err != nil) != tc.err {
+			t.Fatalf("%s: err=%v", tc.name, err)
+		}
+		if got != tc.want {
+			t.Fatalf("%s: got %+v, want %+v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestParseVMStat(t *testing.T) {
+	at := time.Unix(1700000000, 0)
+	vm, err := parseVMStat("nr_free_pages 12345\npgfault 987654\npgmajfault 4321\npgrefill 0\n", at)
+	if err != nil || vm.PgMajFault != 4321 || !vm.At.Equal(at) {
+		t.Fatalf("vm=%+v err=%v", vm, err)
+	}
+	if _, err := parseVMStat("nr_free_pages 12345\npgfault 987654\n", at); err == nil {
+		t.Fatal("want an error without pgmajfault")
+	}
+}
+
+func TestMajorFaultRate(t *testing.T) {
+	t0 := time.Unix(1700000000, 0)
+	for _, tc := range []struct {
+		name      string
+		prev, cur VMStat
+		want      float64
+	}{
+		{"rate", VMStat{At: t0, PgMajFault: 100}, VMStat{At: t0.Add(2 * time.Second), PgMajFault: 150}, 25},
+		{"idle", VMStat{At: t0, PgMajFault: 100}, VMStat{At: t0.Add(time.Second), PgMajFault: 100}, 0},
+		// after a reboot or wrap the counter starts over
+		{"backwards", VMStat{At: t0, PgMajFault: 100}, VMStat{At: t0.Add(time.Second), PgMajFault: 40}, 0},
+		{"same instant", VMStat{At: t0, PgMajFault: 100}, VMStat{At: t0, PgMajFault: 200}, 0},
+		{"clock went back", VMStat{At: t0, PgMajFault: 100}, VMStat{At: t0.Add(-time.Second), PgMajFault: 200}, 0},
+	} {
+		if got := MajorFaultRate(tc.prev, tc.cur); got != tc.want {
+			t.Fatalf("%s: got %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package host
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// clkTck is USER_HZ; it is 100 on every Linux platform clawtop targets.
+const clkTck = 100
+
+type Process struct {
+	PID      int
+	Comm     string
+	Cmdline  string
+	State    string
+	RSSBytes uint64
+	// CPUTicks is utime+stime in clock ticks since the process started.
+	CPUTicks uint64
+	At       time.Time
+
+	// CPUPercent is filled in by ProcessCPUPercent; 100 means one full core.
+	CPUPercent float64
+}
+
+// ReadProcesses lists all processes visible in /proc. Processes that exit
+// while being read are skipped.
+func ReadProcesses() ([]Process, error) {
+	dirs, err := filepath.Glob("/proc/[0-9]*")
+	if err != nil {
+		return nil, err
+	}
+	at := time.Now()
+	page := uint64(os.Getpagesize())
+	out := make([]Process, 0, len(dirs))
+	for _, d := range dirs {
+		pid, err := strconv.Atoi(filepath.Base(d))
+		if err != nil {
+			continue
+		}
+		b, err := os.ReadFile(filepath.Join(d, "stat"))
+		if err != nil {
+			continue
+		}
+		p, ok := parseProcStat(string(b), page)
+		if !ok {
+			continue
+		}
+		p.PID = pid
+		p.At = at
+		if cl, err := os.ReadFile(filepath.Join(d, "cmdline")); err == nil {
+			p.Cmdline = strings.TrimSpace(strings.ReplaceAll(string(cl), "\x00", " "))
+		}
+		out = append(out, p)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].PID < out[j].PID })
+	return out, nil
+}
+
+// parseProcStat parses /proc/<pid>/stat; page is the page size the RSS
+// count is in.
+func parseProcStat(s string, page uint64) (Process, bool) {
+	// pid (comm) state ppid ... ; comm may contain spaces and parens
+	lp := strings.IndexByte(s, '(')
+	rp := strings.LastIndexByte(s, ')')
+	if lp < 0 || rp < lp {
+		return Process{}, false
+	}
+	f := strings.Fields(s[rp+1:])
+	// f[0] is field 3 (state); utime=14, stime=15, rss=24
+	if len(f) < 22 {
+		return Process{}, false
+	}
+	utime, _ := strconv.ParseUint(f[11], 10, 64)
+	stime, _ := strconv.ParseUint(f[12], 10, 64)
+	rss, _ := strconv.ParseUint(f[21], 10, 64)
+	return Process{Comm: s[lp+1 : rp], State: f[0], CPUTicks: utime + stime, RSSBytes: rss * page}, true
+}
+
+// ProcessCPUPercent sets CPUPercent on cur from the matching PIDs in prev.
+// New processes (or reused PIDs) get 0 until the next sample.
+func ProcessCPUPercent(prev, cur []Process) {
+	byPID := make(map[int]Process, len(prev))
+	for _, p := range prev {
+		byPID[p.PID] = p
+	}
+	for i := range cur {
+		p, ok := byPID[cur[i].PID]
+		if !ok || cur[i].CPUTicks < p.CPUTicks {
+			continue
+		}
+		dt := cur[i].At.Sub(p.At).Seconds()
+		if dt <= 0 {
+			continue
+		}
+		cur[i].CPUPercent = float64(cur[i].CPUTicks-p.CPUTicks) / clkTck / dt * 100
+	}
+}
@@ -0,0 +1,68 @@
+package host
+
+import (
+	"testing"
+	"time"
+)
+
+// statTail is /proc/<pid>/stat after the comm: state R, utime 250,
+// stime 50 and an RSS of 2560 pages.
+const statTail = " R 1 1234 1234 0 -1 4194560 1000 0 5 0 250 50 0 0 20 0 1 0 12345 10485760 2560 18446744073709551615 1 1 0 0 0 0 0 0 0 0 0 0 17 3 0 0\n"
+
+func TestParseProcStat(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		in   string
+		ok   bool
+		comm string
+	}{
+		{"plain", "1234 (nginx)" + statTail, true, "nginx"},
+		{"spaces and parens in comm", "1234 (web ) (worker)" + statTail, true, "web ) (worker"},
+		{"empty comm", "1234 ()" + statTail, true, ""},
+		{"short line", "1234 (nginx) R 1 1234 1234 0 -1 4194560 1000 0 5 0 250 50", false, ""},
+		{"no comm", "1234 nginx R 1 1234", false, ""},
+		{"unclosed comm", "1234 (nginx R 1 1234", false, ""},
+		{"empty", "", false, ""},
+	} {
+		p, ok := parseProcStat(tc.in, 4096)
+		if ok != tc.ok {
+			t.Fatalf("%s: ok=%v", tc.name, ok)
+		}
+		if !ok {
+			continue
+		}
+		if p.Comm != tc.comm || p.State != "R" || p.CPUTicks != 300 || p.RSSBytes != 2560*4096 {
+			t.Fatalf("%s: %+v", tc.name, p)
+		}
+	}
+	// the RSS count is in pages of whatever size the kernel uses
+	if p, _ := parseProcStat("1 (init)"+statTail, 16384); p.RSSBytes != 2560*16384 {
+		t.Fatalf("16K pages: rss=%d", p.RSSBytes)
+	}
+}
+
+func TestProcessCPUPercent(t *testing.T) {
+	t0 := time.Unix(1700000000, 0)
+	t1 := t0.Add(2 * time.Second)
+	prev := []Process{
+		{PID: 1, CPUTicks: 1000, At: t0},
+		{PID: 2, CPUTicks: 5000, At: t0},
+		{PID: 3, CPUTicks: 100, At: t1},
+	}
+	cur := []Process{
+		// 100 ticks over 2s is half a core
+		{PID: 1, CPUTicks: 1100, At: t1},
+		// reused PID: a new process with fewer ticks than the old one
+		{PID: 2, CPUTicks: 20, At: t1},
+		// sampled at the same instant, so no rate yet
+		{PID: 3, CPUTicks: 200, At: t1},
+		// new since prev
+		{PID: 4, CPUTicks: 50, At: t1},
+	}
+	ProcessCPUPercent(prev, cur)
+	for i, want := range []float64{50, 0, 0, 0} {
+		if cur[i].CPUPercent != want {
+			t.Fatalf("pid %d: got %v, want %v", cur[i].PID, cur[i].CPUPercent, want)
+		}
+	}
+}
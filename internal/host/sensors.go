@@ -0,0 +1,126 @@
+package host
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultSysRoot is where sysfs is mounted on a normal host.
+const DefaultSysRoot = "/sys"
+
+// Temp is one hwmon temperature input.
+type Temp struct {
+	Chip    string // hwmon "name", e.g. coretemp, k10temp, cpu_thermal
+	Label   string // tempN_label, or tempN when the driver has no label
+	Celsius float64
+	CritC   float64 // 0 when the driver exposes no critical trip point
+}
+
+// Sensors are temperatures and CPU frequency, read from sysfs.
+//
+// Frequencies are averaged over all CPUs with cpufreq; the max is the
+// hardware limit (cpuinfo_max_freq), so Cur well below Max under load
+// usually means thermal or power throttling.
+type Sensors struct {
+	Temps         []Temp
+	CPUFreqMHz    float64
+	CPUMaxFreqMHz float64
+	// ThrottleCount sums thermal_throttle/core_throttle_count over CPUs
+	// (x86 only). It is cumulative since boot.
+	ThrottleCount uint64
+	// NewThrottles is how much ThrottleCount grew since the previous
+	// reading; ReadSensors leaves it zero.
+	NewThrottles uint64
+}
+
+// MaxTemp returns the hottest sensor, or false if there are none.
+func (s Sensors) MaxTemp() (Temp, bool) {
+	if len(s.Temps) == 0 {
+		return Temp{}, false
+	}
+	best := s.Temps[0]
+	for _, t := range s.Temps[1:] {
+		if t.Celsius > best.Celsius {
+			best = t
+		}
+	}
+	return best, true
+}
+
+// ReadSensors reads hwmon temperatures and cpufreq from sysRoot (normally
+// DefaultSysRoot). Missing files are not errors: containers and VMs often
+// expose neither, in which case the zero Sensors is returned.
+func ReadSensors(sysRoot string) (Sensors, error) {
+	if sysRoot == "" {
+		sysRoot = DefaultSysRoot
+	}
+	var s Sensors
+
+	chips, _ := filepath.Glob(filepath.Join(sysRoot, "class", "hwmon", "hwmon*"))
+	sort.Strings(chips)
+	for _, chip := range chips {
+		name := readTrimmed(filepath.Join(chip, "name"))
+		inputs, _ := filepath.Glob(filepath.Join(chip, "temp*_input"))
+		sort.Strings(inputs)
+		for _, in := range inputs {
+			milli, ok := readInt(in)
+			if !ok {
+				continue
+			}
+			base := strings.TrimSuffix(filepath.Base(in), "_input")
+			label := readTrimmed(filepath.Join(chip, base+"_label"))
+			if label == "" {
+				label = base
+			}
+			t := Temp{Chip: name, Label: label, Celsius: float64(milli) / 1000}
+			if crit, ok := readInt(filepath.Join(chip, base+"_crit")); ok {
+				t.CritC = float64(crit) / 1000
+			}
+			s.Temps = append(s.Temps, t)
+		}
+	}
+
+	cpus, _ := filepath.Glob(filepath.Join(sysRoot, "devices", "system", "cpu", "cpu[0-9]*"))
+	var curSum, maxSum float64
+	n := 0
+	for _, cpu := range cpus {
+		if cur, ok := readInt(filepath.Join(cpu, "cpufreq", "scaling_cur_freq")); ok {
+			mx, _ := readInt(filepath.Join(cpu, "cpufreq", "cpuinfo_max_freq"))
+			// values are kHz
+			curSum += float64(cur) / 1000
+			maxSum += float64(mx) / 1000
+			n++
+		}
+		if c, ok := readInt(filepath.Join(cpu, "thermal_throttle", "core_throttle_count")); ok {
+			s.ThrottleCount += uint64(c)
+		}
+	}
+	if n > 0 {
+		s.CPUFreqMHz = curSum / float64(n)
+		s.CPUMaxFreqMHz = maxSum / float64(n)
+	}
+	return s, nil
+}
+
+func readTrimmed(path string) string {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(b))
+}
+
+func readInt(path string) (int64, bool) {
+	s := readTrimmed(path)
+	if s == "" {
+		return 0, false
+	}
+	x, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return x, true
+}
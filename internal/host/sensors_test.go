@@ -0,0 +1,70 @@
+package host
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReadSensors(t *testing.T) {
+	root := t.TempDir()
+	hw0 := filepath.Join(root, "class", "hwmon", "hwmon0")
+	writeFile(t, filepath.Join(hw0, "name"), "coretemp\n")
+	writeFile(t, filepath.Join(hw0, "temp1_input"), "54000\n")
+	writeFile(t, filepath.Join(hw0, "temp1_label"), "Package id 0\n")
+	writeFile(t, filepath.Join(hw0, "temp1_crit"), "100000\n")
+	writeFile(t, filepath.Join(hw0, "temp2_input"), "81500\n")
+	hw1 := filepath.Join(root, "class", "hwmon", "hwmon1")
+	writeFile(t, filepath.Join(hw1, "name"), "nvme\n")
+	writeFile(t, filepath.Join(hw1, "temp1_input"), "40000\n")
+
+	for _, cpu := range []string{"cpu0", "cpu1"} {
+		d := filepath.Join(root, "devices", "system", "cpu", cpu)
+		writeFile(t, filepath.Join(d, "cpufreq", "scaling_cur_freq"), "1200000\n")
+		writeFile(t, filepath.Join(d, "cpufreq", "cpuinfo_max_freq"), "3400000\n")
+		writeFile(t, filepath.Join(d, "thermal_throttle", "core_throttle_count"), "3\n")
+	}
+	// not a cpu directory
+	writeFile(t, filepath.Join(root, "devices", "system", "cpu", "cpufreq", "boost"), "1\n")
+
+	s, err := ReadSensors(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Temps) != 3 {
+		t.Fatalf("temps=%d", len(s.Temps))
+	}
+	if s.Temps[0].Label != "Package id 0" || s.Temps[0].CritC != 100 {
+		t.Fatalf("temp0=%#v", s.Temps[0])
+	}
+	mx, ok := s.MaxTemp()
+	if !ok || mx.Celsius != 81.5 || mx.Label != "temp2" || mx.Chip != "coretemp" {
+		t.Fatalf("max=%#v", mx)
+	}
+	if s.CPUFreqMHz != 1200 || s.CPUMaxFreqMHz != 3400 {
+		t.Fatalf("freq=%v/%v", s.CPUFreqMHz, s.CPUMaxFreqMHz)
+	}
+	if s.ThrottleCount != 6 {
+		t.Fatalf("throttle=%d", s.ThrottleCount)
+	}
+}
+
+func TestReadSensors_Empty(t *testing.T) {
+	s, err := ReadSensors(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := s.MaxTemp(); ok || s.CPUFreqMHz != 0 {
+		t.Fatalf("expected zero sensors: %#v", s)
+	}
+}
@@ -0,0 +1,85 @@
+package list
+
+import (
+	"time"
+
+	"github.com/cl4wb0rg/clawtop/internal/openclaw"
+)
+
+// Datasets names the list subcommands.
+var Datasets = []string{"sessions", "subagents", "crons", "tasks"}
+
+// optTime is nil for a missing time, so it sorts last and prints empty.
+func optTime(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return *t
+}
+
+// withInstance puts the instance column first in the defaults when
+// several installations are read.
+func withInstance(t *Table, multi bool) *Table {
+	if multi {
+		t.Default = append([]string{"instance"}, t.Default...)
+	}
+	return t
+}
+
+func Sessions(ss []openclaw.Session, multi bool) *Table {
+	t := &Table{
+		Columns:     []string{"instance", "key", "label", "model", "provider", "updatedAt", "inputTokens", "outputTokens", "totalTokens"},
+		Default:     []string{"key", "label", "model", "updatedAt", "totalTokens"},
+		DefaultSort: "-updatedAt",
+	}
+	for _, s := range ss {
+		t.Rows = append(t.Rows, []any{s.Instance, s.Key, s.Label, s.Model, s.Provider, s.UpdatedAt, s.InputTokens, s.OutputTokens, s.TotalTokens})
+	}
+	return withInstance(t, multi)
+}
+
+// Subagents has a runtime column: from start to finish, or to now while
+// running.
+func Subagents(rs []openclaw.SubagentRun, multi bool, now time.Time) *Table {
+	t := &Table{
+		Columns:     []string{"instance", "runId", "sessionKey", "label", "status", "model", "createdAt", "startedAt", "finishedAt", "runtime", "task"},
+		Default:     []string{"label", "status", "model", "createdAt", "runtime", "task"},
+		DefaultSort: "-createdAt",
+	}
+	for _, r := range rs {
+		var runtime any
+		if r.StartedAt != nil {
+			end := now
+			if r.FinishedAt != nil {
+				end = *r.FinishedAt
+			}
+			runtime = end.Sub(*r.StartedAt)
+		}
+		t.Rows = append(t.Rows, []any{r.Instance, r.RunID, r.ChildSessionKey, r.Label, r.Status(), r.Model, r.CreatedAt, optTime(r.StartedAt), optTime(r.FinishedAt), runtime, r.Task})
+	}
+	return withInstance(t, multi)
+}
+
+func Crons(cs []openclaw.CronJob, multi bool) *Table {
+	t := &Table{
+		Columns:     []string{"instance", "id", "name", "enabled", "schedule", "tz", "nextRunAt", "lastRunAt", "lastStatus", "lastError"},
+		Default:     []string{"name", "enabled", "schedule", "nextRunAt", "lastRunAt", "lastStatus", "lastError"},
+		DefaultSort: "-lastRunAt",
+	}
+	for _, c := range cs {
+		t.Rows = append(t.Rows, []any{c.Instance, c.ID, c.Name, c.Enabled, c.Schedule, c.TZ, optTime(c.NextRun), optTime(c.LastRun), c.LastStatus, c.LastError})
+	}
+	return withInstance(t, multi)
+}
+
+func Tasks(ts []openclaw.Task, multi bool) *Table {
+	t := &Table{
+		Columns:     []string{"instance", "at", "level", "source", "title", "detail"},
+		Default:     []string{"at", "level", "source", "title", "detail"},
+		DefaultSort: "-at",
+	}
+	for _, k := range ts {
+		t.Rows = append(t.Rows, []any{k.Instance, k.At, string(k.Level), string(k.Source), k.Title, k.Detail})
+	}
+	return withInstance(t, multi)
+}
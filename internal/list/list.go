@@ -0,0 +1,291 @@
+// Package list prints one dataset (sessions, subagents, crons or tasks) as
+// a table, CSV, TSV or JSON for the list subcommands.
+//
+// Column names are the field names of the JSON snapshot (see
+// schema/snapshot.v1.json) and are matched case-insensitively.
+package list
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rivo/uniseg"
+)
+
+// Formats are the output formats Write accepts.
+var Formats = []string{"table", "csv", "tsv", "json"}
+
+// maxCell caps table cells, in terminal cells; the other formats are
+// never cut.
+const maxCell = 60
+
+// Table is a dataset as named columns. Values are strings, int64,
+// float64, bool, time.Time, time.Duration or nil.
+type Table struct {
+	Columns []string
+	Rows    [][]any
+	// Default lists the columns shown when none are asked for.
+	Default []string
+	// DefaultSort is the Sort spec used when none is asked for.
+	DefaultSort string
+}
+
+// Select keeps the named columns, in that order; no names means Default.
+func (t *Table) Select(names []string) error {
+	if len(names) == 0 {
+		names = t.Default
+	}
+	idx := make([]int, len(names))
+	cols := make([]string, len(names))
+	for i, n := range names {
+		j := t.column(n)
+		if j < 0 {
+			return fmt.Errorf("unknown column %q (have %s)", n, strings.Join(t.Columns, ", "))
+		}
+		idx[i], cols[i] = j, t.Columns[j]
+	}
+	for r, row := range t.Rows {
+		out := make([]any, len(idx))
+		for i, j := range idx {
+			out[i] = row[j]
+		}
+		t.Rows[r] = out
+	}
+	t.Columns = cols
+	return nil
+}
+
+func (t *Table) column(name string) int {
+	for i, c := range t.Columns {
+		if strings.EqualFold(c, name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// Sort orders the rows by a column, descending when spec starts with "-";
+// no spec means DefaultSort. Empty values sort last either way. The column
+// need not be selected, as long as Sort runs before Select.
+func (t *Table) Sort(spec string) error {
+	if spec == "" {
+		spec = t.DefaultSort
+	}
+	if spec == "" {
+		return nil
+	}
+	desc := strings.HasPrefix(spec, "-")
+	j := t.column(strings.TrimPrefix(spec, "-"))
+	if j < 0 {
+		return fmt.Errorf("unknown sort column %q (have %s)", strings.TrimPrefix(spec, "-"), strings.Join(t.Columns, ", "))
+	}
+	sort.SliceStable(t.Rows, func(a, b int) bool {
+		x, y := t.Rows[a][j], t.Rows[b][j]
+		if isEmpty(x) || isEmpty(y) {
+			return !isEmpty(x) && isEmpty(y)
+		}
+		if desc {
+			return less(y, x)
+		}
+		return less(x, y)
+	})
+	return nil
+}
+
+// Limit keeps the first n rows; n <= 0 keeps all.
+func (t *Table) Limit(n int) {
+	if n > 0 && len(t.Rows) > n {
+		t.Rows = t.Rows[:n]
+	}
+}
+
+func isEmpty(v any) bool {
+	switch v := v.(type) {
+	case nil:
+		return true
+	case time.Time:
+		return v.IsZero()
+	}
+	return false
+}
+
+func less(x, y any) bool {
+	switch x := x.(type) {
+	case string:
+		return strings.ToLower(x) < strings.ToLower(y.(string))
+	case int64:
+		return x < y.(int64)
+	case float64:
+		return x < y.(float64)
+	case bool:
+		return !x && y.(bool)
+	case time.Time:
+		return x.Before(y.(time.Time))
+	case time.Duration:
+		return x < y.(time.Duration)
+	}
+	return false
+}
+
+// Write prints t in format, which is one of Formats.
+func (t *Table) Write(w io.Writer, format string) error {
+	switch format {
+	case "table":
+		return t.writeTable(w)
+	case "csv":
+		cw := csv.NewWriter(w)
+		cw.Write(t.Columns)
+		for _, row := range t.Rows {
+			cw.Write(t.texts(row, text))
+		}
+		cw.Flush()
+		return cw.Error()
+	case "tsv":
+		// TSV has no quoting: tabs and newlines inside values become spaces
+		lines := []string{strings.Join(t.Columns, "\t")}
+		for _, row := range t.Rows {
+			lines = append(lines, strings.Join(t.texts(row, func(v any) string { return flatten(text(v)) }), "\t"))
+		}
+		_, err := io.WriteString(w, strings.Join(lines, "\n")+"\n")
+		return err
+	case "json":
+		return t.writeJSON(w)
+	}
+	return fmt.Errorf("unknown format %q (want one of %s)", format, strings.Join(Formats, ", "))
+}
+
+func (t *Table) texts(row []any, f func(any) string) []string {
+	out := make([]string, len(row))
+	for i, v := range row {
+		out[i] = f(v)
+	}
+	return out
+}
+
+// text is a value for CSV and TSV: times in RFC 3339, nothing for nil.
+func text(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case time.Time:
+		if v.IsZero() {
+			return ""
+		}
+		return v.Format(time.RFC3339)
+	case time.Duration:
+		return v.Round(time.Second).String()
+	case float64:
+		return fmt.Sprintf("%.2f", v)
+	}
+	return fmt.Sprint(v)
+}
+
+func flatten(s string) string {
+	return strings.Join(strings.FieldsFunc(s, func(r rune) bool { return r == '\t' || r == '\n' || r == '\r' }), " ")
+}
+
+// writeTable aligns the columns for reading: local times, "-" for empty
+// values, long cells cut with "…".
+func (t *Table) writeTable(w io.Writer) error {
+	cells := [][]string{t.Columns}
+	for _, row := range t.Rows {
+		cells = append(cells, t.texts(row, func(v any) string {
+			switch v := v.(type) {
+			case time.Time:
+				if !v.IsZero() {
+					return v.Local().Format("2006-01-02 15:04:05")
+				}
+			case bool:
+				if v {
+					return "yes"
+				}
+				return "no"
+			}
+			s := flatten(text(v))
+			if s == "" {
+				return "-"
+			}
+			return truncate(s, maxCell)
+		}))
+	}
+	widths := make([]int, len(t.Columns))
+	for _, row := range cells {
+		for i, c := range row {
+			widths[i] = max(widths[i], uniseg.StringWidth(c))
+		}
+	}
+	var b strings.Builder
+	for _, row := range cells {
+		for i, c := range row {
+			b.WriteString(c)
+			if i < len(row)-1 {
+				b.WriteString(strings.Repeat(" ", widths[i]-uniseg.StringWidth(c)+2))
+			}
+		}
+		b.WriteString("\n")
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func truncate(s string, w int) string {
+	if uniseg.StringWidth(s) <= w {
+		return s
+	}
+	var b strings.Builder
+	used := 0
+	g := uniseg.NewGraphemes(s)
+	for g.Next() && used+g.Width() <= w-1 {
+		b.WriteString(g.Str())
+		used += g.Width()
+	}
+	return b.String() + "…"
+}
+
+// writeJSON writes an array of objects with keys in column order. Times
+// are RFC 3339, durations are seconds and empty times are null.
+func (t *Table) writeJSON(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("[")
+	for r, row := range t.Rows {
+		if r > 0 {
+			b.WriteString(",")
+		}
+		b.WriteString("\n  {")
+		for i, v := range row {
+			switch x := v.(type) {
+			case time.Time:
+				if x.IsZero() {
+					v = nil
+				}
+			case time.Duration:
+				v = x.Seconds()
+			}
+			k, _ := json.Marshal(t.Columns[i])
+			val, err := json.Marshal(v)
+			if err != nil {
+				return err
+			}
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			b.Write(k)
+			b.WriteString(": ")
+			b.Write(val)
+		}
+		b.WriteString("}")
+	}
+	if len(t.Rows) > 0 {
+		b.WriteString("\n")
+	}
+	b.WriteString("]\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,86 @@
+package list
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cl4wb0rg/clawtop/internal/openclaw"
+)
+
+func crons() *Table {
+	at := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	return Crons([]openclaw.CronJob{
+		{ID: "a", Name: "nightly", Enabled: true, LastRun: &at, LastStatus: "error", LastError: "timeout\nafter 300s"},
+		{ID: "b", Name: "Heartbeat", LastStatus: "ok"},
+		{ID: "c", Name: "weekly", Enabled: true, LastRun: ptr(at.Add(time.Hour)), LastStatus: "ok"},
+	}, false)
+}
+
+func ptr(t time.Time) *time.Time { return &t }
+
+func TestSortSelectLimit(t *testing.T) {
+	tb := crons()
+	if err := tb.Sort("-lastRunAt"); err != nil {
+		t.Fatal(err)
+	}
+	if err := tb.Select([]string{"NAME", "lastRunAt"}); err != nil {
+		t.Fatal(err)
+	}
+	// newest first, the job that never ran last
+	if tb.Columns[0] != "name" || tb.Rows[0][0] != "weekly" || tb.Rows[2][0] != "Heartbeat" {
+		t.Fatalf("%v %v", tb.Columns, tb.Rows)
+	}
+	tb.Limit(1)
+	if len(tb.Rows) != 1 {
+		t.Fatalf("limit: %d rows", len(tb.Rows))
+	}
+	if err := crons().Sort("nope"); err == nil {
+		t.Fatal("expected error for unknown sort column")
+	}
+	if err := crons().Select([]string{"nope"}); err == nil {
+		t.Fatal("expected error for unknown column")
+	}
+	// no --sort: newest run first
+	tb = crons()
+	if err := tb.Sort(""); err != nil || tb.Rows[0][2] != "weekly" || tb.Rows[2][2] != "Heartbeat" {
+		t.Fatalf("default sort: %v %v", err, tb.Rows)
+	}
+	tb = crons()
+	tb.Sort("name")
+	if tb.Rows[0][2] != "Heartbeat" {
+		t.Fatalf("string sort is case-insensitive, got %v first", tb.Rows[0][2])
+	}
+}
+
+func TestWrite(t *testing.T) {
+	want := map[string]string{
+		"csv":   "name,lastRunAt,lastError\nnightly,2026-01-02T03:04:05Z,\"timeout\nafter 300s\"\nHeartbeat,,\n",
+		"tsv":   "name\tlastRunAt\tlastError\nnightly\t2026-01-02T03:04:05Z\ttimeout after 300s\nHeartbeat\t\t\n",
+		"json":  "[\n  {\"name\": \"nightly\", \"lastRunAt\": \"2026-01-02T03:04:05Z\", \"lastError\": \"timeout\\nafter 300s\"},\n  {\"name\": \"Heartbeat\", \"lastRunAt\": null, \"lastError\": \"\"}\n]\n",
+		"table": "",
+	}
+	for format, w := range want {
+		tb := crons()
+		tb.Select([]string{"name", "lastRunAt", "lastError"})
+		tb.Limit(2)
+		var b bytes.Buffer
+		if err := tb.Write(&b, format); err != nil {
+			t.Fatal(err)
+		}
+		if format == "table" {
+			lines := strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n")
+			if len(lines) != 3 || !strings.HasPrefix(lines[2], "Heartbeat  -") || strings.Index(lines[0], "lastRunAt") != strings.Index(lines[2], "-") {
+				t.Fatalf("table:\n%s", b.String())
+			}
+			continue
+		}
+		if b.String() != w {
+			t.Fatalf("%s: got\n%q\nwant\n%q", format, b.String(), w)
+		}
+	}
+	if err := crons().Write(&bytes.Buffer{}, "xml"); err == nil {
+		t.Fatal("expected error for unknown format")
+	}
+}
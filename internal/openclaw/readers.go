@@ -11,6 +11,7 @@ import (
 	"sort"
 	"strings"
 	"time"
+	"unicode/utf8"
 )
 
 func ReadSessionsJSON(path string) ([]Session, error) {
@@ -20,6 +21,7 @@ func ReadSessionsJSON(path string) ([]Session, error) {
 	}
 	// sessions.json is a map[sessionKey]sessionState
 	var raw map[string]struct {
+		SessionID    string `json:"sessionId"`
 		Label        string `json:"label"`
 		Model        string `json:"model"`
 		ModelProvider string `json:"modelProvider"`
@@ -35,6 +37,7 @@ func ReadSessionsJSON(path string) ([]Session, error) {
 	for k, v := range raw {
 		out = append(out, Session{
 			Key:          k,
+			SessionID:    v.SessionID,
 			Label:        v.Label,
 			Model:        v.Model,
 			Provider:     v.ModelProvider,
@@ -159,7 +162,8 @@ func ReadCronJobs(path string) ([]CronJob, error) {
 }
 
 // ReadLatestCronRun reads the most recent "finished" event from a cron runs jsonl file.
-// It returns status and either error or first line of summary.
+// It returns status and either error or the full summary; the UI shows the
+// first line in tables and the rest in the detail pane.
 func ReadLatestCronRun(path string) (Task, bool, error) {
 	f, err := os.Open(path)
 	if err != nil {
@@ -194,7 +198,7 @@ func ReadLatestCronRun(path string) (Task, bool, error) {
 		if rec.Status == "error" {
 			lvl = LevelError
 		}
-		detail := firstLine(rec.Summary)
+		detail := strings.TrimSpace(rec.Summary)
 		if rec.Error != "" {
 			detail = rec.Error
 		}
@@ -203,6 +207,73 @@ func ReadLatestCronRun(path string) (Task, bool, error) {
 	return Task{}, false, nil
 }
 
+// ReadCronFailStreak counts the "finished" events with status error at the
+// end of a cron runs jsonl file, i.e. how many runs in a row have failed.
+func ReadCronFailStreak(path string) (int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	lines, err := tailLines(f, 200)
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	for i := len(lines) - 1; i >= 0; i-- {
+		var rec struct {
+			Action string `json:"action"`
+			Status string `json:"status"`
+		}
+		if err := json.Unmarshal([]byte(strings.TrimSpace(lines[i])), &rec); err != nil || rec.Action != "finished" {
+			continue
+		}
+		if rec.Status != "error" {
+			break
+		}
+		n++
+	}
+	return n, nil
+}
+
+// ReadCronRuns reads the "finished" events of a cron runs jsonl file,
+// oldest first. A run starts at runAtMs, else durationMs before it ended.
+func ReadCronRuns(path string) ([]CronRun, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var out []CronRun
+	for _, ln := range strings.Split(string(b), "\n") {
+		var rec struct {
+			TS         int64  `json:"ts"`
+			Action     string `json:"action"`
+			Status     string `json:"status"`
+			Error      string `json:"error"`
+			Summary    string `json:"summary"`
+			JobID      string `json:"jobId"`
+			RunAtMs    int64  `json:"runAtMs"`
+			DurationMs int64  `json:"durationMs"`
+			SessionKey string `json:"sessionKey"`
+			SessionID  string `json:"sessionId"`
+		}
+		if err := json.Unmarshal([]byte(strings.TrimSpace(ln)), &rec); err != nil || rec.Action != "finished" {
+			continue
+		}
+		r := CronRun{JobID: rec.JobID, End: time.UnixMilli(rec.TS), Status: rec.Status, Error: rec.Error, Summary: rec.Summary, SessionKey: rec.SessionKey, SessionID: rec.SessionID}
+		switch {
+		case rec.RunAtMs != 0 && rec.RunAtMs <= rec.TS:
+			r.Start = time.UnixMilli(rec.RunAtMs)
+		default:
+			r.Start = r.End.Add(-time.Duration(rec.DurationMs) * time.Millisecond)
+		}
+		out = append(out, r)
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].End.Before(out[j].End) })
+	return out, nil
+}
+
 func ReadTokenSamples(path string, max int) ([]TokenSample, error) {
 	b, err := os.ReadFile(path)
 	if err != nil {
@@ -236,6 +307,10 @@ func ReadTokenSamples(path string, max int) ([]TokenSample, error) {
 	return samples, nil
 }
 
+// maxToolDetail caps how much of each tool result is kept; outputs such as
+// file reads can be megabytes.
+const maxToolDetail = 4096
+
 // ReadToolTasks reads recent tool results from a session jsonl file.
 func ReadToolTasks(sessionJSONL string, maxLines int, maxTasks int) ([]Task, error) {
 	f, err := os.Open(sessionJSONL)
@@ -280,7 +355,7 @@ func ReadToolTasks(sessionJSONL string, maxLines int, maxTasks int) ([]Task, err
 		}
 		detail := ""
 		if len(rec.Message.Content) > 0 {
-			detail = firstLine(rec.Message.Content[0].Text)
+			detail = clip(strings.TrimSpace(rec.Message.Content[0].Text), maxToolDetail)
 		}
 		tasks = append(tasks, Task{At: at, Level: lvl, Source: SourceTool, Title: rec.Message.ToolName, Detail: detail})
 	}
@@ -291,16 +366,80 @@ func ReadToolTasks(sessionJSONL string, maxLines int, maxTasks int) ([]Task, err
 	return tasks, nil
 }
 
+// ReadToolCalls pairs the tool calls in a transcript with their results,
+// oldest first. A result whose call isn't in the file starts and ends at
+// the result.
+func ReadToolCalls(transcript string) ([]ToolCall, error) {
+	f, err := os.Open(transcript)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var out []ToolCall
+	started := map[string]time.Time{}
+	sc := bufio.NewScanner(f)
+	// tool results (file reads, command output) make for long lines
+	sc.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for sc.Scan() {
+		var rec struct {
+			Type    string `json:"type"`
+			Message struct {
+				Role       string `json:"role"`
+				ToolCallID string `json:"toolCallId"`
+				ToolName   string `json:"toolName"`
+				IsError    bool   `json:"isError"`
+				Timestamp  int64  `json:"timestamp"`
+				Content    []struct {
+					Type string `json:"type"`
+					ID   string `json:"id"`
+					Text string `json:"text"`
+				} `json:"content"`
+			} `json:"message"`
+		}
+		if err := json.Unmarshal(sc.Bytes(), &rec); err != nil || rec.Type != "message" || rec.Message.Timestamp == 0 {
+			continue
+		}
+		m := rec.Message
+		at := time.UnixMilli(m.Timestamp)
+		switch m.Role {
+		case "assistant":
+			for _, c := range m.Content {
+				if c.Type == "toolCall" && c.ID != "" {
+					started[c.ID] = at
+				}
+			}
+		case "toolResult":
+			tc := ToolCall{ID: m.ToolCallID, Name: m.ToolName, Start: at, End: at, IsError: m.IsError}
+			if st, ok := started[m.ToolCallID]; ok && !st.After(at) {
+				tc.Start = st
+			}
+			if m.IsError && len(m.Content) > 0 {
+				tc.Error = clip(strings.TrimSpace(m.Content[0].Text), 256)
+			}
+			out = append(out, tc)
+		}
+	}
+	return out, sc.Err()
+}
+
+// TranscriptFile is where an agent's session transcript is kept.
+func TranscriptFile(openclawRoot, agent, sessionID string) string {
+	return filepath.Join(openclawRoot, "agents", agent, "sessions", sessionID+".jsonl")
+}
+
 func CronRunFile(cronRunsDir, jobID string) string {
 	return filepath.Join(cronRunsDir, jobID+".jsonl")
 }
 
-func firstLine(s string) string {
-	s = strings.ReplaceAll(s, "\r\n", "\n")
-	if idx := strings.IndexByte(s, '\n'); idx >= 0 {
-		return strings.TrimSpace(s[:idx])
+// clip cuts s to at most n bytes without splitting a UTF-8 sequence.
+func clip(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
 	}
-	return strings.TrimSpace(s)
+	return s[:n] + "…"
 }
 
 // tailLines reads up to n last lines from r (which must be an *os.File).
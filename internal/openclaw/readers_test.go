@@ -55,6 +55,44 @@ func TestReadCronJobs(t *testing.T) {
 	}
 }
 
+func TestReadCronFailStreak(t *testing.T) {
+	p := filepath.Join(t.TempDir(), "a.jsonl")
+	b := []byte("" +
+		`{"ts":1,"action":"finished","status":"error","jobId":"a"}` + "\n" +
+		`{"ts":2,"action":"finished","status":"ok","jobId":"a"}` + "\n" +
+		`{"ts":3,"action":"finished","status":"error","jobId":"a"}` + "\n" +
+		`{"ts":4,"action":"started","jobId":"a"}` + "\n" +
+		`{"ts":5,"action":"finished","status":"error","jobId":"a"}` + "\n")
+	if err := os.WriteFile(p, b, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	n, err := ReadCronFailStreak(p)
+	if err != nil || n != 2 {
+		t.Fatalf("n=%d err=%v", n, err)
+	}
+}
+
+func TestReadCronRuns(t *testing.T) {
+	p := filepath.Join(t.TempDir(), "a.jsonl")
+	b := []byte("" +
+		`{"ts":5000,"action":"finished","status":"error","error":"boom","jobId":"a","runAtMs":2000,"sessionId":"s9"}` + "\n" +
+		`{"ts":1000,"action":"started","jobId":"a"}` + "\n" +
+		`{"ts":1500,"action":"finished","status":"ok","jobId":"a","durationMs":500}` + "\n")
+	if err := os.WriteFile(p, b, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	runs, err := ReadCronRuns(p)
+	if err != nil || len(runs) != 2 {
+		t.Fatalf("runs=%+v err=%v", runs, err)
+	}
+	if runs[0].Start.UnixMilli() != 1000 || runs[0].Status != "ok" {
+		t.Fatalf("first=%+v", runs[0])
+	}
+	if runs[1].Start.UnixMilli() != 2000 || runs[1].Error != "boom" || runs[1].SessionID != "s9" {
+		t.Fatalf("second=%+v", runs[1])
+	}
+}
+
 func TestReadTokenSamples(t *testing.T) {
 	tmp := t.TempDir()
 	p := filepath.Join(tmp, "tokens.jsonl")
@@ -103,3 +141,28 @@ func TestReadToolTasks(t *testing.T) {
 	}
 	_ = time.Second
 }
+
+func TestReadToolCalls(t *testing.T) {
+	p := filepath.Join(t.TempDir(), "s.jsonl")
+	b := []byte("" +
+		`{"type":"message","message":{"role":"assistant","timestamp":1000,"content":[{"type":"toolCall","id":"c1","name":"exec"},{"type":"toolCall","id":"c2","name":"read"}]}}` + "\n" +
+		`{"type":"message","message":{"role":"toolResult","toolCallId":"c1","toolName":"exec","timestamp":1800,"content":[{"type":"text","text":"ok"}]}}` + "\n" +
+		`{"type":"message","message":{"role":"toolResult","toolCallId":"c2","toolName":"read","isError":true,"timestamp":1900,"content":[{"type":"text","text":"ENOENT"}]}}` + "\n" +
+		`{"type":"message","message":{"role":"toolResult","toolCallId":"c3","toolName":"write","timestamp":2500,"content":[]}}` + "\n")
+	if err := os.WriteFile(p, b, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	calls, err := ReadToolCalls(p)
+	if err != nil || len(calls) != 3 {
+		t.Fatalf("calls=%+v err=%v", calls, err)
+	}
+	if c := calls[0]; c.Name != "exec" || c.Start.UnixMilli() != 1000 || c.End.UnixMilli() != 1800 || c.IsError {
+		t.Fatalf("exec=%+v", c)
+	}
+	if c := calls[1]; !c.IsError || c.Error != "ENOENT" {
+		t.Fatalf("read=%+v", c)
+	}
+	if c := calls[2]; !c.Start.Equal(c.End) {
+		t.Fatalf("unpaired result=%+v", c)
+	}
+}
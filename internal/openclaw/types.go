@@ -1,19 +1,29 @@
 package openclaw
 
-import "time"
+import (
+	"strings"
+	"time"
+)
 
 type Session struct {
-	Key         string
-	Label       string
-	Model       string
-	Provider    string
-	UpdatedAt   time.Time
-	InputTokens int64
+	// Instance names the installation the record came from when several
+	// are watched at once; the readers leave it empty. The other record
+	// types carry it too.
+	Instance string
+	Key      string
+	// SessionID names the session's transcript (see TranscriptFile).
+	SessionID    string
+	Label        string
+	Model        string
+	Provider     string
+	UpdatedAt    time.Time
+	InputTokens  int64
 	OutputTokens int64
-	TotalTokens int64
+	TotalTokens  int64
 }
 
 type SubagentRun struct {
+	Instance        string
 	RunID           string
 	ChildSessionKey string
 	Label           string
@@ -25,15 +35,45 @@ type SubagentRun struct {
 }
 
 type CronJob struct {
-	ID        string
-	Name      string
-	Enabled   bool
-	Schedule  string
-	TZ        string
-	NextRun   *time.Time
-	LastRun   *time.Time
+	Instance   string
+	ID         string
+	Name       string
+	Enabled    bool
+	Schedule   string
+	TZ         string
+	NextRun    *time.Time
+	LastRun    *time.Time
 	LastStatus string
 	LastError  string
+	// ConsecutiveErrors is how many runs in a row have failed, from the
+	// runs log; ReadCronJobs leaves it zero.
+	ConsecutiveErrors int
+}
+
+// CronRun is one finished run from a cron job's runs log.
+type CronRun struct {
+	JobID   string
+	Start   time.Time
+	End     time.Time
+	Status  string
+	Error   string
+	Summary string
+	// SessionKey and SessionID name the run's session, when the log
+	// records them.
+	SessionKey string
+	SessionID  string
+}
+
+// ToolCall is one tool invocation from a transcript, from the assistant's
+// call to its result.
+type ToolCall struct {
+	ID      string
+	Name    string
+	Start   time.Time
+	End     time.Time
+	IsError bool
+	// Error is the start of the result text, when IsError.
+	Error string
 }
 
 type TaskLevel string
@@ -54,11 +94,12 @@ const (
 )
 
 type Task struct {
-	At      time.Time
-	Level   TaskLevel
-	Source  TaskSource
-	Title   string
-	Detail  string
+	Instance string
+	At       time.Time
+	Level    TaskLevel
+	Source   TaskSource
+	Title    string
+	Detail   string
 }
 
 type TokenSample struct {
@@ -66,3 +107,48 @@ type TokenSample struct {
 	OpenClawTotal int64
 	ClaudeCostUSD float64
 }
+
+// Status derives "queued", "running" or "done" from the run timestamps.
+func (r SubagentRun) Status() string {
+	switch {
+	case r.FinishedAt != nil:
+		return "done"
+	case r.StartedAt != nil:
+		return "running"
+	}
+	return "queued"
+}
+
+// PrimaryModel guesses the model the installation mostly runs on: the
+// main session's, else the most common one.
+func PrimaryModel(s []Session) string {
+	for _, sess := range s {
+		if sess.Key == "agent:main:main" && sess.Model != "" {
+			return sess.Model
+		}
+	}
+	// fallback most common
+	m := map[string]int{}
+	for _, sess := range s {
+		if sess.Model != "" {
+			m[sess.Model]++
+		}
+	}
+	best := ""
+	bestN := 0
+	for k, n := range m {
+		if n > bestN {
+			best, bestN = k, n
+		}
+	}
+	return best
+}
+
+// AgentOf is the agent id in a session key ("agent:<id>:..."), or "".
+func AgentOf(key string) string {
+	parts := strings.SplitN(key, ":", 3)
+	if len(parts) < 2 || parts[0] != "agent" {
+		return ""
+	}
+	return parts[1]
+}
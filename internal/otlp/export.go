@@ -0,0 +1,191 @@
+package otlp
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cl4wb0rg/clawtop/internal/config"
+)
+
+// the OTLP/JSON shapes of an ExportTraceServiceRequest; IDs are hex and
+// 64-bit integers are decimal strings
+
+type request struct {
+	ResourceSpans []resourceSpans `json:"resourceSpans"`
+}
+
+type resourceSpans struct {
+	Resource   resource     `json:"resource"`
+	ScopeSpans []scopeSpans `json:"scopeSpans"`
+}
+
+type resource struct {
+	Attributes []keyValue `json:"attributes"`
+}
+
+type scopeSpans struct {
+	Scope scope      `json:"scope"`
+	Spans []jsonSpan `json:"spans"`
+}
+
+type scope struct {
+	Name string `json:"name"`
+}
+
+type jsonSpan struct {
+	TraceID           string     `json:"traceId"`
+	SpanID            string     `json:"spanId"`
+	ParentSpanID      string     `json:"parentSpanId,omitempty"`
+	Name              string     `json:"name"`
+	Kind              int        `json:"kind"`
+	StartTimeUnixNano string     `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string     `json:"endTimeUnixNano"`
+	Attributes        []keyValue `json:"attributes"`
+	Status            *status    `json:"status,omitempty"`
+}
+
+type keyValue struct {
+	Key   string   `json:"key"`
+	Value anyValue `json:"value"`
+}
+
+type anyValue struct {
+	StringValue *string `json:"stringValue,omitempty"`
+	IntValue    *string `json:"intValue,omitempty"`
+	BoolValue   *bool   `json:"boolValue,omitempty"`
+}
+
+type status struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+const (
+	spanKindInternal = 1
+	statusError      = 2
+)
+
+// ServiceName is the service.name resource attribute of every span.
+const ServiceName = "openclaw"
+
+// Encode builds an OTLP/JSON export request with one resource per
+// instance, in the order the instances first appear in spans.
+func Encode(spans []Span) ([]byte, error) {
+	var req request
+	idx := map[string]int{}
+	for _, sp := range spans {
+		i, ok := idx[sp.Instance]
+		if !ok {
+			i = len(req.ResourceSpans)
+			idx[sp.Instance] = i
+			req.ResourceSpans = append(req.ResourceSpans, resourceSpans{
+				Resource:   resource{Attributes: attrs([]Attr{{"service.name", ServiceName}, {"openclaw.instance", sp.Instance}})},
+				ScopeSpans: []scopeSpans{{Scope: scope{Name: "clawtop"}, Spans: []jsonSpan{}}},
+			})
+		}
+		js := jsonSpan{
+			TraceID:           hex.EncodeToString(sp.TraceID[:]),
+			SpanID:            hex.EncodeToString(sp.SpanID[:]),
+			Name:              sp.Name,
+			Kind:              spanKindInternal,
+			StartTimeUnixNano: strconv.FormatInt(sp.Start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(sp.End.UnixNano(), 10),
+			Attributes:        attrs(sp.Attrs),
+		}
+		if sp.ParentID != ([8]byte{}) {
+			js.ParentSpanID = hex.EncodeToString(sp.ParentID[:])
+		}
+		if sp.Failed {
+			js.Status = &status{Code: statusError, Message: sp.Error}
+		}
+		ss := &req.ResourceSpans[i].ScopeSpans[0]
+		ss.Spans = append(ss.Spans, js)
+	}
+	if req.ResourceSpans == nil {
+		req.ResourceSpans = []resourceSpans{}
+	}
+	return json.Marshal(req)
+}
+
+// attrs encodes as, leaving out empty strings.
+func attrs(as []Attr) []keyValue {
+	out := []keyValue{}
+	for _, a := range as {
+		var v anyValue
+		switch x := a.Value.(type) {
+		case string:
+			if x == "" {
+				continue
+			}
+			v.StringValue = &x
+		case int64:
+			s := strconv.FormatInt(x, 10)
+			v.IntValue = &s
+		case bool:
+			v.BoolValue = &x
+		default:
+			s := fmt.Sprint(x)
+			v.StringValue = &s
+		}
+		out = append(out, keyValue{a.Key, v})
+	}
+	return out
+}
+
+// Exporter posts spans to one collector.
+type Exporter struct {
+	URL     string
+	Headers map[string]string
+	Client  *http.Client
+}
+
+// New builds an Exporter for c. As for OTEL_EXPORTER_OTLP_ENDPOINT in the
+// OpenTelemetry SDKs, the endpoint is a base URL that /v1/traces is
+// appended to; one that already ends in /v1/traces is used as it is.
+func New(c config.OTLP) *Exporter {
+	u := c.Endpoint
+	if p, err := url.Parse(u); err == nil && !strings.HasSuffix(strings.TrimSuffix(p.Path, "/"), "/v1/traces") {
+		p.Path = strings.TrimSuffix(p.Path, "/") + "/v1/traces"
+		u = p.String()
+	}
+	return &Exporter{URL: u, Headers: c.Headers, Client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// Export sends spans in one request. Nothing is sent when there are none.
+func (e *Exporter) Export(ctx context.Context, spans []Span) error {
+	if len(spans) == 0 {
+		return nil
+	}
+	body, err := Encode(spans)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range e.Headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := e.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("otlp: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("otlp: %s: %s: %s", e.URL, resp.Status, strings.TrimSpace(string(msg)))
+	}
+	io.Copy(io.Discard, resp.Body)
+	return nil
+}
@@ -0,0 +1,189 @@
+package otlp
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cl4wb0rg/clawtop/internal/collect"
+	"github.com/cl4wb0rg/clawtop/internal/config"
+	"github.com/cl4wb0rg/clawtop/internal/openclaw"
+)
+
+func writeFile(t *testing.T, path, body string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// fixture is an installation with one finished subagent run that made two
+// tool calls, and one failed cron run.
+func fixture(t *testing.T) collect.Instance {
+	root := t.TempDir()
+	sessions := filepath.Join(root, "agents", "main", "sessions")
+	writeFile(t, filepath.Join(sessions, "sessions.json"), `{
+  "agent:main:subagent:x": {"sessionId": "sub1", "model": "opus", "updatedAt": 1700000010000},
+  "agent:main:cron:c1": {"sessionId": "cronsess", "model": "gpt-5.2", "updatedAt": 1700000010000}
+}`)
+	writeFile(t, filepath.Join(sessions, "sub1.jsonl"), ""+
+		`{"type":"message","message":{"role":"assistant","timestamp":1700000001000,"content":[{"type":"toolCall","id":"t1","name":"exec"}]}}`+"\n"+
+		`{"type":"message","message":{"role":"toolResult","toolCallId":"t1","toolName":"exec","timestamp":1700000002000,"content":[{"type":"text","text":"ok"}]}}`+"\n"+
+		`{"type":"message","message":{"role":"assistant","timestamp":1700000003000,"content":[{"type":"toolCall","id":"t2","name":"read"}]}}`+"\n"+
+		`{"type":"message","message":{"role":"toolResult","toolCallId":"t2","toolName":"read","isError":true,"timestamp":1700000004000,"content":[{"type":"text","text":"ENOENT"}]}}`+"\n")
+	writeFile(t, filepath.Join(root, "subagents", "runs.json"), `{"version":1,"runs":{
+  "r1": {"runId":"r1","childSessionKey":"agent:main:subagent:x","label":"research","model":"opus","createdAt":1700000000000,"startedAt":1700000000500,"finishedAt":1700000005000},
+  "r2": {"runId":"r2","childSessionKey":"agent:main:subagent:y","createdAt":1700000000000,"startedAt":1700000000500}
+}}`)
+	writeFile(t, filepath.Join(root, "cron", "jobs.json"), `{"version":1,"jobs":[{"id":"c1","name":"nightly","enabled":true,"schedule":{"kind":"cron","expr":"0 3 * * *"},"state":{}}]}`)
+	writeFile(t, filepath.Join(root, "cron", "runs", "c1.jsonl"), ""+
+		`{"ts":1600000000000,"action":"finished","status":"ok","jobId":"c1","durationMs":1000}`+"\n"+
+		`{"ts":1700000009000,"action":"finished","status":"error","error":"timeout","jobId":"c1","runAtMs":1700000006000}`+"\n")
+	p, err := openclaw.DiscoverPaths(root, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return collect.Instance{Name: "prod", Paths: p}
+}
+
+func attr(sp Span, key string) any {
+	for _, a := range sp.Attrs {
+		if a.Key == key {
+			return a.Value
+		}
+	}
+	return nil
+}
+
+func TestRuns(t *testing.T) {
+	in := fixture(t)
+	d := collect.Read(in, config.Default().Limits)
+	spans := Runs(in, d, time.UnixMilli(1690000000000))
+	if len(spans) != 4 {
+		t.Fatalf("want subagent, 2 tools and cron, got %+v", spans)
+	}
+	run, exec, read, cron := spans[0], spans[1], spans[2], spans[3]
+	if run.Name != "subagent research" || attr(run, "gen_ai.request.model") != "opus" || run.ParentID != ([8]byte{}) {
+		t.Fatalf("run=%+v", run)
+	}
+	if exec.TraceID != run.TraceID || exec.ParentID != run.SpanID || attr(exec, "gen_ai.tool.name") != "exec" || exec.End.Sub(exec.Start) != time.Second {
+		t.Fatalf("exec=%+v", exec)
+	}
+	if !read.Failed || read.Error != "ENOENT" {
+		t.Fatalf("read=%+v", read)
+	}
+	if cron.Name != "cron nightly" || !cron.Failed || cron.Error != "timeout" || cron.End.Sub(cron.Start) != 3*time.Second || attr(cron, "gen_ai.request.model") != "gpt-5.2" {
+		t.Fatalf("cron=%+v", cron)
+	}
+	if again := Runs(in, d, time.UnixMilli(1690000000000)); again[0].SpanID != run.SpanID || again[1].SpanID != exec.SpanID {
+		t.Fatal("IDs differ between reads")
+	}
+	if n := len(Runs(in, d, time.UnixMilli(1700000008000))); n != 1 {
+		t.Fatalf("since: got %d spans", n)
+	}
+}
+
+func TestRunsSharedTranscript(t *testing.T) {
+	root := t.TempDir()
+	sessions := filepath.Join(root, "agents", "main", "sessions")
+	writeFile(t, filepath.Join(sessions, "sessions.json"), `{"agent:main:cron:c1": {"sessionId": "shared", "updatedAt": 1700000020000}}`)
+	writeFile(t, filepath.Join(sessions, "shared.jsonl"), ""+
+		`{"type":"message","message":{"role":"assistant","timestamp":1700000001000,"content":[{"type":"toolCall","id":"t1","name":"exec"}]}}`+"\n"+
+		`{"type":"message","message":{"role":"toolResult","toolCallId":"t1","toolName":"exec","timestamp":1700000001500,"content":[]}}`+"\n"+
+		`{"type":"message","message":{"role":"assistant","timestamp":1700000011000,"content":[{"type":"toolCall","id":"t2","name":"read"}]}}`+"\n"+
+		`{"type":"message","message":{"role":"toolResult","toolCallId":"t2","toolName":"read","timestamp":1700000011500,"content":[]}}`+"\n")
+	writeFile(t, filepath.Join(root, "cron", "jobs.json"), `{"version":1,"jobs":[{"id":"c1","name":"poll","enabled":true,"schedule":{"kind":"every","everyMs":10000},"state":{}}]}`)
+	writeFile(t, filepath.Join(root, "cron", "runs", "c1.jsonl"), ""+
+		`{"ts":1700000002000,"action":"finished","status":"ok","jobId":"c1","runAtMs":1700000000500,"sessionKey":"agent:main:cron:c1","sessionId":"shared"}`+"\n"+
+		`{"ts":1700000012000,"action":"finished","status":"ok","jobId":"c1","runAtMs":1700000010500,"sessionKey":"agent:main:cron:c1","sessionId":"shared"}`+"\n")
+	p, err := openclaw.DiscoverPaths(root, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	in := collect.Instance{Name: "prod", Paths: p}
+	spans := Runs(in, collect.Read(in, config.Default().Limits), time.Time{})
+	if len(spans) != 4 {
+		t.Fatalf("want two runs with a tool each, got %+v", spans)
+	}
+	// each run gets only the call made while it ran
+	for i, tool := range []string{"exec", "read"} {
+		run, call := spans[2*i], spans[2*i+1]
+		if call.ParentID != run.SpanID || attr(call, "gen_ai.tool.name") != tool {
+			t.Fatalf("run %d: %+v has %+v", i, run, call)
+		}
+	}
+}
+
+func TestNewURL(t *testing.T) {
+	for in, want := range map[string]string{
+		"http://gw:4318":            "http://gw:4318/v1/traces",
+		"http://gw:4318/":           "http://gw:4318/v1/traces",
+		"http://gw:4318/otlp":       "http://gw:4318/otlp/v1/traces",
+		"http://gw:4318/otlp/":      "http://gw:4318/otlp/v1/traces",
+		"http://gw:4318/v1/traces":  "http://gw:4318/v1/traces",
+		"https://gw/otlp/v1/traces": "https://gw/otlp/v1/traces",
+	} {
+		if got := New(config.OTLP{Endpoint: in}).URL; got != want {
+			t.Fatalf("%s: got %s, want %s", in, got, want)
+		}
+	}
+}
+
+func TestExport(t *testing.T) {
+	in := fixture(t)
+	spans := Runs(in, collect.Read(in, config.Default().Limits), time.Time{})
+	var got struct {
+		ResourceSpans []struct {
+			Resource struct {
+				Attributes []keyValue `json:"attributes"`
+			} `json:"resource"`
+			ScopeSpans []struct {
+				Spans []jsonSpan `json:"spans"`
+			} `json:"scopeSpans"`
+		} `json:"resourceSpans"`
+	}
+	var path, auth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, auth = r.URL.Path, r.Header.Get("Authorization")
+		b, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(b, &got); err != nil {
+			t.Errorf("%v: %s", err, b)
+		}
+	}))
+	defer srv.Close()
+
+	e := New(config.OTLP{Endpoint: srv.URL, Headers: map[string]string{"Authorization": "Bearer x"}})
+	if err := e.Export(context.Background(), spans); err != nil {
+		t.Fatal(err)
+	}
+	if path != "/v1/traces" || auth != "Bearer x" {
+		t.Fatalf("path=%s auth=%s", path, auth)
+	}
+	if len(got.ResourceSpans) != 1 || *got.ResourceSpans[0].Resource.Attributes[1].Value.StringValue != "prod" {
+		t.Fatalf("%+v", got)
+	}
+	js := got.ResourceSpans[0].ScopeSpans[0].Spans
+	if len(js) != 5 || len(js[0].TraceID) != 32 || len(js[0].SpanID) != 16 || js[0].ParentSpanID != "" || js[1].ParentSpanID != js[0].SpanID {
+		t.Fatalf("%+v", js)
+	}
+	if js[0].StartTimeUnixNano != "1700000000500000000" || js[2].Status == nil || js[2].Status.Code != statusError {
+		t.Fatalf("%+v", js)
+	}
+
+	bad := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "nope", http.StatusBadRequest)
+	}))
+	defer bad.Close()
+	if err := New(config.OTLP{Endpoint: bad.URL + "/custom"}).Export(context.Background(), spans); err == nil {
+		t.Fatal("want an error for a 400")
+	}
+}
@@ -0,0 +1,195 @@
+// Package otlp turns OpenClaw agent activity into OpenTelemetry spans and
+// sends them to a collector over OTLP/HTTP, as JSON, for "clawtop traces".
+//
+// Each finished subagent run and cron run is a trace: the run is the root
+// span and the tool calls in its transcript are its children. IDs are
+// derived from the run, so exporting a run twice yields the same spans.
+package otlp
+
+import (
+	"crypto/sha256"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cl4wb0rg/clawtop/internal/collect"
+	"github.com/cl4wb0rg/clawtop/internal/openclaw"
+)
+
+// Span is one span before encoding. Attribute values are strings, int64s
+// or bools.
+type Span struct {
+	// Instance names the installation; it becomes a resource attribute.
+	Instance string
+	TraceID  [16]byte
+	SpanID   [8]byte
+	// ParentID is zero for a run's root span.
+	ParentID [8]byte
+	Name     string
+	Start    time.Time
+	End      time.Time
+	Attrs    []Attr
+	// Failed marks the span as an error, with Error as the message.
+	Failed bool
+	Error  string
+}
+
+type Attr struct {
+	Key   string
+	Value any
+}
+
+// maxAttr caps free-text attributes such as the task and error texts.
+const maxAttr = 512
+
+// Runs rebuilds the subagent and cron runs of one instance that finished
+// after since, each followed by its tool calls. Transcripts and run logs
+// that can't be read leave the runs without children, or out.
+func Runs(in collect.Instance, d collect.Data, since time.Time) []Span {
+	if d.Err != nil {
+		return nil
+	}
+	inst := collect.InstanceName(in.Name)
+	root := in.Paths.OpenClawRoot
+	sessions := map[string]openclaw.Session{}
+	for _, s := range d.Sessions {
+		sessions[s.Key] = s
+	}
+	// a session's cron runs share its transcript; read it once
+	transcripts := map[string][]openclaw.ToolCall{}
+	calls := func(path string) []openclaw.ToolCall {
+		c, ok := transcripts[path]
+		if !ok {
+			var err error
+			if c, err = openclaw.ReadToolCalls(path); err != nil {
+				c = nil
+			}
+			transcripts[path] = c
+		}
+		return c
+	}
+	var out []Span
+
+	for _, r := range d.Subagents {
+		if r.FinishedAt == nil || !r.FinishedAt.After(since) {
+			continue
+		}
+		start := r.CreatedAt
+		if r.StartedAt != nil {
+			start = *r.StartedAt
+		}
+		name := r.Label
+		if name == "" {
+			name = r.RunID
+		}
+		sp := Span{Instance: inst, Name: "subagent " + name, Start: start, End: *r.FinishedAt}
+		sp.TraceID, sp.SpanID = ids(inst, "subagent", r.RunID)
+		sp.Attrs = []Attr{
+			{"openclaw.kind", "subagent"},
+			{"openclaw.run_id", r.RunID},
+			{"openclaw.session_key", r.ChildSessionKey},
+			{"openclaw.label", r.Label},
+			{"openclaw.task", clip(r.Task)},
+			{"gen_ai.request.model", r.Model},
+		}
+		out = append(out, sp)
+		if s, ok := sessions[r.ChildSessionKey]; ok && s.SessionID != "" {
+			out = append(out, tools(sp, "subagent", r.RunID, calls(openclaw.TranscriptFile(root, openclaw.AgentOf(s.Key), s.SessionID)))...)
+		}
+	}
+
+	for _, c := range d.Crons {
+		runs, err := openclaw.ReadCronRuns(openclaw.CronRunFile(in.Paths.CronRunsDir, c.ID))
+		if err != nil {
+			continue
+		}
+		for _, r := range runs {
+			if !r.End.After(since) {
+				continue
+			}
+			runID := c.ID + ":" + r.Start.Format("20060102T150405.000")
+			sp := Span{Instance: inst, Name: "cron " + c.Name, Start: r.Start, End: r.End}
+			sp.TraceID, sp.SpanID = ids(inst, "cron", runID)
+			sp.Attrs = []Attr{
+				{"openclaw.kind", "cron"},
+				{"openclaw.job_id", c.ID},
+				{"openclaw.job_name", c.Name},
+				{"openclaw.cron.status", r.Status},
+			}
+			// older logs don't name the run's session; the job's own session
+			// still has the model
+			run, ok := sessions[r.SessionKey]
+			s := run
+			if !ok {
+				s, ok = sessions["agent:main:cron:"+c.ID]
+			}
+			if ok {
+				sp.Attrs = append(sp.Attrs, Attr{"openclaw.session_key", s.Key}, Attr{"gen_ai.request.model", s.Model})
+			}
+			if r.Status == "error" {
+				sp.Failed, sp.Error = true, clip(r.Error)
+				sp.Attrs = append(sp.Attrs, Attr{"error.type", "cron_error"})
+			}
+			out = append(out, sp)
+			id := r.SessionID
+			if id == "" {
+				id = run.SessionID
+			}
+			if id != "" {
+				agent := openclaw.AgentOf(r.SessionKey)
+				if agent == "" {
+					agent = "main"
+				}
+				out = append(out, tools(sp, "cron", runID, calls(openclaw.TranscriptFile(root, agent, id)))...)
+			}
+		}
+	}
+	return out
+}
+
+// tools are the calls, all from one transcript, made while parent ran,
+// as its children.
+func tools(parent Span, kind, runID string, calls []openclaw.ToolCall) []Span {
+	var out []Span
+	for i, c := range calls {
+		if c.End.Before(parent.Start) || c.Start.After(parent.End) {
+			continue
+		}
+		// results without a call id are told apart by their place in the
+		// transcript, which only grows
+		callID := c.ID
+		if callID == "" {
+			callID = "#" + strconv.Itoa(i)
+		}
+		sp := Span{Instance: parent.Instance, TraceID: parent.TraceID, ParentID: parent.SpanID, Name: "tool " + c.Name, Start: c.Start, End: c.End}
+		_, sp.SpanID = ids(parent.Instance, kind, runID, callID)
+		sp.Attrs = []Attr{{"gen_ai.tool.name", c.Name}, {"gen_ai.tool.call.id", c.ID}}
+		if c.IsError {
+			sp.Failed, sp.Error = true, clip(c.Error)
+			sp.Attrs = append(sp.Attrs, Attr{"error.type", "tool_error"})
+		}
+		out = append(out, sp)
+	}
+	return out
+}
+
+// ids derives a trace and span ID from parts, so they are stable across
+// exports.
+func ids(parts ...string) (trace [16]byte, span [8]byte) {
+	h := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	copy(trace[:], h[:16])
+	copy(span[:], h[16:24])
+	return trace, span
+}
+
+func clip(s string) string {
+	s = strings.TrimSpace(s)
+	if len(s) <= maxAttr {
+		return s
+	}
+	n := maxAttr
+	for n > 0 && s[n]&0xC0 == 0x80 {
+		n--
+	}
+	return s[:n] + "…"
+}
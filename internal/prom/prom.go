@@ -0,0 +1,238 @@
+// Package prom writes what clawtop reads in the Prometheus text exposition
+// format (version 0.0.4) for "clawtop serve".
+//
+// Series about an installation carry an openclaw_instance label (the
+// profile name, or "default"); "instance" itself is left to Prometheus,
+// which sets it to the scraped target.
+package prom
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cl4wb0rg/clawtop/internal/collect"
+	"github.com/cl4wb0rg/clawtop/internal/host"
+)
+
+// ContentType is the exposition format's media type.
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// set collects samples by metric family, so each family's HELP and TYPE
+// are written once, before its samples.
+type set struct {
+	order []string
+	fams  map[string]*family
+}
+
+type family struct {
+	help, typ string
+	samples   []sample
+}
+
+type sample struct {
+	labels [][2]string
+	value  float64
+}
+
+func (s *set) add(name, typ, help string, v float64, labels ...string) {
+	f := s.fams[name]
+	if f == nil {
+		f = &family{help: help, typ: typ}
+		s.fams[name] = f
+		s.order = append(s.order, name)
+	}
+	smp := sample{value: v}
+	for i := 0; i+1 < len(labels); i += 2 {
+		smp.labels = append(smp.labels, [2]string{labels[i], labels[i+1]})
+	}
+	f.samples = append(f.samples, smp)
+}
+
+func (s *set) gauge(name, help string, v float64, labels ...string) {
+	s.add(name, "gauge", help, v, labels...)
+}
+
+func (s *set) counter(name, help string, v float64, labels ...string) {
+	s.add(name, "counter", help, v, labels...)
+}
+
+func (s *set) write(w io.Writer) error {
+	var b bytes.Buffer
+	for _, name := range s.order {
+		f := s.fams[name]
+		b.WriteString("# HELP " + name + " " + escapeHelp(f.help) + "\n")
+		b.WriteString("# TYPE " + name + " " + f.typ + "\n")
+		for _, smp := range f.samples {
+			b.WriteString(name)
+			if len(smp.labels) > 0 {
+				b.WriteString("{")
+				for i, l := range smp.labels {
+					if i > 0 {
+						b.WriteString(",")
+					}
+					b.WriteString(l[0] + `="` + escapeLabel(l[1]) + `"`)
+				}
+				b.WriteString("}")
+			}
+			b.WriteString(" " + strconv.FormatFloat(smp.value, 'f', -1, 64) + "\n")
+		}
+	}
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+func escapeHelp(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(s)
+}
+
+func escapeLabel(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`).Replace(s)
+}
+
+func seconds(t time.Time) float64 {
+	return float64(t.UnixNano()) / 1e9
+}
+
+func bool01(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// Write writes the host metrics and every instance in ds. Filters are not
+// applied: alerting should see everything.
+func Write(w io.Writer, now time.Time, h host.HostMetrics, ds []collect.Data) error {
+	s := &set{fams: map[string]*family{}}
+	writeHost(s, h)
+	for _, d := range ds {
+		writeInstance(s, now, d)
+	}
+	return s.write(w)
+}
+
+func writeHost(s *set, h host.HostMetrics) {
+	s.gauge("clawtop_host_cpu_percent", "Host CPU busy percent since the previous scrape.", h.CPUPercent)
+	s.gauge("clawtop_host_memory_used_bytes", "Host memory in use (total minus available).", float64(h.MemUsedBytes))
+	s.gauge("clawtop_host_memory_total_bytes", "Host memory total.", float64(h.MemTotalBytes))
+	s.gauge("clawtop_host_swap_used_bytes", "Host swap in use.", float64(h.SwapUsedBytes))
+	s.gauge("clawtop_host_swap_total_bytes", "Host swap total.", float64(h.SwapTotalBytes))
+	s.gauge("clawtop_host_load1", "Host 1-minute load average.", h.Load1)
+	s.gauge("clawtop_host_load5", "Host 5-minute load average.", h.Load5)
+	s.gauge("clawtop_host_load15", "Host 15-minute load average.", h.Load15)
+	s.gauge("clawtop_host_major_faults_per_second", "Host major page faults per second since the previous scrape.", h.MajFaultsPerSec)
+	if h.HasPSI {
+		for _, r := range []struct {
+			name string
+			p    host.Pressure
+		}{{"cpu", h.PSICPU}, {"memory", h.PSIMemory}, {"io", h.PSIIO}} {
+			for _, v := range []struct {
+				kind, window string
+				v            float64
+			}{{"some", "10", r.p.SomeAvg10}, {"some", "60", r.p.SomeAvg60}, {"some", "300", r.p.SomeAvg300}, {"full", "10", r.p.FullAvg10}, {"full", "60", r.p.FullAvg60}, {"full", "300", r.p.FullAvg300}} {
+				s.gauge("clawtop_host_pressure_percent", "Percent of wall time stalled on a resource (PSI), averaged over window seconds.", v.v, "resource", r.name, "kind", v.kind, "window", v.window)
+			}
+		}
+	}
+	for _, t := range h.Sensors.Temps {
+		s.gauge("clawtop_host_temperature_celsius", "Host temperature sensor reading.", t.Celsius, "chip", t.Chip, "label", t.Label)
+	}
+	if h.Sensors.CPUFreqMHz > 0 {
+		s.gauge("clawtop_host_cpu_frequency_hertz", "Host average current CPU frequency.", h.Sensors.CPUFreqMHz*1e6)
+	}
+}
+
+func writeInstance(s *set, now time.Time, d collect.Data) {
+	inst := collect.InstanceName(d.Instance)
+	s.gauge("clawtop_up", "Whether the installation's sessions.json could be read.", bool01(d.Err == nil), "openclaw_instance", inst)
+	for _, src := range d.Sources {
+		s.gauge("clawtop_source_up", "Whether a file clawtop reads could be read (0 when missing or unreadable).", bool01(src.Err == nil), "openclaw_instance", inst, "source", src.Name)
+		if !src.ModTime.IsZero() {
+			s.gauge("clawtop_source_modified_timestamp_seconds", "When a file clawtop reads was last modified.", seconds(src.ModTime), "openclaw_instance", inst, "source", src.Name)
+		}
+	}
+	if d.Err != nil {
+		return
+	}
+
+	type mp struct{ model, provider string }
+	counts := map[mp]int{}
+	for _, r := range d.Sessions {
+		counts[mp{r.Model, r.Provider}]++
+	}
+	keys := make([]mp, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].model != keys[j].model {
+			return keys[i].model < keys[j].model
+		}
+		return keys[i].provider < keys[j].provider
+	})
+	for _, k := range keys {
+		s.gauge("clawtop_sessions", "Sessions by model and provider.", float64(counts[k]), "openclaw_instance", inst, "model", k.model, "provider", k.provider)
+	}
+	for _, r := range d.Sessions {
+		l := []string{"openclaw_instance", inst, "session", r.Key, "model", r.Model}
+		s.counter("clawtop_session_input_tokens_total", "Input tokens used by a session.", float64(r.InputTokens), l...)
+		s.counter("clawtop_session_output_tokens_total", "Output tokens used by a session.", float64(r.OutputTokens), l...)
+		s.counter("clawtop_session_tokens_total", "Tokens used by a session.", float64(r.TotalTokens), l...)
+		s.gauge("clawtop_session_updated_timestamp_seconds", "When a session was last updated.", seconds(r.UpdatedAt), l...)
+	}
+
+	status := map[string]int{"queued": 0, "running": 0, "done": 0}
+	for _, r := range d.Subagents {
+		status[r.Status()]++
+	}
+	for _, st := range []string{"queued", "running", "done"} {
+		s.gauge("clawtop_subagent_runs", "Subagent runs in runs.json by status.", float64(status[st]), "openclaw_instance", inst, "status", st)
+	}
+	for _, r := range d.Subagents {
+		if r.Status() == "running" {
+			s.gauge("clawtop_subagent_running_seconds", "How long a running subagent has been running.", now.Sub(*r.StartedAt).Seconds(), "openclaw_instance", inst, "run_id", r.RunID, "label", r.Label, "model", r.Model)
+		}
+	}
+
+	for _, c := range d.Crons {
+		l := []string{"openclaw_instance", inst, "job", c.ID, "name", c.Name}
+		s.gauge("clawtop_cron_enabled", "Whether a cron job is enabled.", bool01(c.Enabled), l...)
+		if c.LastRun != nil {
+			s.gauge("clawtop_cron_last_run_timestamp_seconds", "When a cron job last ran.", seconds(*c.LastRun), l...)
+		}
+		if c.NextRun != nil {
+			s.gauge("clawtop_cron_next_run_timestamp_seconds", "When a cron job runs next.", seconds(*c.NextRun), l...)
+		}
+		if c.LastStatus != "" {
+			s.gauge("clawtop_cron_last_status", "A cron job's last status as OpenClaw reports it; always 1.", 1, append(l, "status", c.LastStatus)...)
+		}
+		s.gauge("clawtop_cron_last_error", "Whether a cron job's last run failed.", bool01(c.LastStatus == "error"), l...)
+		s.gauge("clawtop_cron_consecutive_failures", "How many runs of a cron job in a row have failed.", float64(c.ConsecutiveErrors), l...)
+	}
+
+	if n := len(d.TokenSamples); n > 0 {
+		t := d.TokenSamples[n-1]
+		s.gauge("clawtop_openclaw_tokens", "OpenClaw token total from the newest dashboard sample.", float64(t.OpenClawTotal), "openclaw_instance", inst)
+		s.gauge("clawtop_claude_cost_usd", "Claude cost in USD from the newest dashboard sample.", t.ClaudeCostUSD, "openclaw_instance", inst)
+	}
+}
+
+// Handler serves /metrics from read, which is called once per scrape.
+func Handler(read func(now time.Time) (host.HostMetrics, []collect.Data)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		now := time.Now()
+		h, ds := read(now)
+		var b bytes.Buffer
+		if err := Write(&b, now, h, ds); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", ContentType)
+		w.Write(b.Bytes())
+	})
+}
@@ -0,0 +1,87 @@
+package prom
+
+import (
+	"errors"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cl4wb0rg/clawtop/internal/collect"
+	"github.com/cl4wb0rg/clawtop/internal/host"
+	"github.com/cl4wb0rg/clawtop/internal/openclaw"
+)
+
+func fixture() (time.Time, host.HostMetrics, []collect.Data) {
+	now := time.Unix(1700000000, 0)
+	started := now.Add(-90 * time.Second)
+	h := host.HostMetrics{CPUPercent: 12.5, MemUsedBytes: 1 << 30, HasPSI: true, PSICPU: host.Pressure{SomeAvg10: 3}}
+	ds := []collect.Data{
+		{
+			Instance: "prod",
+			Sessions: []openclaw.Session{
+				{Key: "agent:main:main", Model: "gpt-5.2", Provider: "openai", TotalTokens: 300},
+				{Key: "agent:main:cron:1", Model: "gpt-5.2", Provider: "openai"},
+				{Key: `odd"key`, Model: "opus", Provider: "anthropic"},
+			},
+			Subagents: []openclaw.SubagentRun{{RunID: "r1", StartedAt: &started}, {RunID: "r2"}},
+			Crons:     []openclaw.CronJob{{ID: "c1", Name: "backup", Enabled: true, LastRun: &now, LastStatus: "error", ConsecutiveErrors: 3}},
+			Sources:   []collect.Source{{Name: "sessions", ModTime: now}, {Name: "tokens", Err: errors.New("gone")}},
+		},
+		{Instance: "staging", Err: errors.New("openclaw root not found")},
+	}
+	return now, h, ds
+}
+
+func TestWrite(t *testing.T) {
+	now, h, ds := fixture()
+	var b strings.Builder
+	if err := Write(&b, now, h, ds); err != nil {
+		t.Fatal(err)
+	}
+	out := b.String()
+	for _, want := range []string{
+		"# TYPE clawtop_host_cpu_percent gauge\nclawtop_host_cpu_percent 12.5\n",
+		"clawtop_host_memory_used_bytes 1073741824\n",
+		`clawtop_host_pressure_percent{resource="cpu",kind="some",window="10"} 3` + "\n",
+		`clawtop_up{openclaw_instance="prod"} 1` + "\n",
+		`clawtop_up{openclaw_instance="staging"} 0` + "\n",
+		`clawtop_source_up{openclaw_instance="prod",source="tokens"} 0` + "\n",
+		`clawtop_sessions{openclaw_instance="prod",model="gpt-5.2",provider="openai"} 2` + "\n",
+		"# TYPE clawtop_session_tokens_total counter\n",
+		`clawtop_session_tokens_total{openclaw_instance="prod",session="agent:main:main",model="gpt-5.2"} 300` + "\n",
+		`session="odd\"key"`,
+		`clawtop_subagent_runs{openclaw_instance="prod",status="running"} 1` + "\n",
+		`clawtop_subagent_runs{openclaw_instance="prod",status="queued"} 1` + "\n",
+		`clawtop_subagent_running_seconds{openclaw_instance="prod",run_id="r1",label="",model=""} 90` + "\n",
+		`clawtop_cron_last_run_timestamp_seconds{openclaw_instance="prod",job="c1",name="backup"} 1700000000` + "\n",
+		`clawtop_cron_last_status{openclaw_instance="prod",job="c1",name="backup",status="error"} 1` + "\n",
+		`clawtop_cron_consecutive_failures{openclaw_instance="prod",job="c1",name="backup"} 3` + "\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("missing %q in\n%s", want, out)
+		}
+	}
+	if n := strings.Count(out, "# TYPE clawtop_up "); n != 1 {
+		t.Fatalf("clawtop_up TYPE written %d times", n)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	_, h, ds := fixture()
+	srv := httptest.NewServer(Handler(func(time.Time) (host.HostMetrics, []collect.Data) { return h, ds }))
+	defer srv.Close()
+	resp, err := srv.Client().Get(srv.URL + "/metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != 200 || resp.Header.Get("Content-Type") != ContentType {
+		t.Fatalf("%d %s", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	if !strings.Contains(string(body), "clawtop_cron_enabled") {
+		t.Fatalf("body:\n%s", body)
+	}
+}
@@ -0,0 +1,312 @@
+// Package query parses clawtop's filter expressions, e.g.
+//
+//	level:error source:cron since:6h model:gpt-5.2 key:~cron
+//
+// An expression is a list of terms that must all match. A term is either
+// field:value or a bare word:
+//
+//   - field:value matches the field case-insensitively; a comma separates
+//     alternatives (level:error,warn) and a leading ~ makes the value a
+//     regular expression (key:~:cron:).
+//   - since:<duration> keeps records newer than now minus the duration
+//     (Go durations plus d for days, e.g. 90m, 6h, 2d).
+//   - a bare word is a substring search over every field; ~word is a regex.
+//   - a leading - negates any term (-key:~:run:, -heartbeat).
+//   - double quotes group values with spaces (label:"nightly report").
+//
+// Terms naming a field a record doesn't have are ignored for that record,
+// so one expression can filter sessions and tasks at the same time.
+package query
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Record is what a Filter matches against.
+type Record struct {
+	// Fields maps lower-case field names to values.
+	Fields map[string]string
+	// At is the record's activity time, used by since:.
+	At time.Time
+}
+
+// Fields lists every field any record type exposes, plus since.
+var Fields = []string{"since", "instance", "key", "label", "model", "provider", "level", "source", "title", "detail", "task", "status", "name", "id", "error", "schedule"}
+
+type term struct {
+	field  string // empty for a bare word
+	negate bool
+	since  time.Duration
+	values []string // lower-cased alternatives
+	re     *regexp.Regexp
+}
+
+// Filter is a parsed expression. The zero Filter matches everything.
+type Filter struct {
+	expr  string
+	terms []term
+}
+
+// Parse parses an expression. An empty expression matches everything.
+func Parse(expr string) (Filter, error) {
+	toks, err := tokenize(expr)
+	if err != nil {
+		return Filter{}, err
+	}
+	f := Filter{expr: strings.TrimSpace(expr)}
+	for _, tok := range toks {
+		t, err := parseTerm(tok)
+		if err != nil {
+			return Filter{}, err
+		}
+		f.terms = append(f.terms, t)
+	}
+	return f, nil
+}
+
+// MustParse is Parse for expressions built in code.
+func MustParse(expr string) Filter {
+	f, err := Parse(expr)
+	if err != nil {
+		panic(err)
+	}
+	return f
+}
+
+func parseTerm(tok string) (term, error) {
+	var t term
+	if strings.HasPrefix(tok, "-") && len(tok) > 1 {
+		t.negate = true
+		tok = tok[1:]
+	}
+	field, val, ok := strings.Cut(tok, ":")
+	if ok && isField(strings.ToLower(field)) {
+		t.field = strings.ToLower(field)
+	} else {
+		// bare word (may itself contain colons, e.g. agent:main)
+		val = tok
+		if strings.HasPrefix(val, "~") {
+			re, err := compileFold(val[1:])
+			if err != nil {
+				return term{}, err
+			}
+			t.re = re
+		} else {
+			t.re = regexp.MustCompile("(?i)" + regexp.QuoteMeta(val))
+		}
+		return t, nil
+	}
+	if val == "" {
+		return term{}, fmt.Errorf("%s: missing value", t.field)
+	}
+	switch {
+	case t.field == "since":
+		d, err := ParseDuration(val)
+		if err != nil {
+			return term{}, fmt.Errorf("since: %w", err)
+		}
+		t.since = d
+	case strings.HasPrefix(val, "~"):
+		re, err := compileFold(val[1:])
+		if err != nil {
+			return term{}, fmt.Errorf("%s: %w", t.field, err)
+		}
+		t.re = re
+	default:
+		for _, v := range strings.Split(val, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				t.values = append(t.values, strings.ToLower(v))
+			}
+		}
+	}
+	return t, nil
+}
+
+// compileFold compiles a case-insensitive regexp, reporting errors against
+// the pattern as the user typed it.
+func compileFold(pat string) (*regexp.Regexp, error) {
+	if _, err := regexp.Compile(pat); err != nil {
+		return nil, fmt.Errorf("bad regex: %w", err)
+	}
+	return regexp.Compile("(?i)" + pat)
+}
+
+func isField(s string) bool {
+	for _, f := range Fields {
+		if f == s {
+			return true
+		}
+	}
+	return false
+}
+
+// tokenize splits on whitespace outside double quotes and drops the quotes.
+func tokenize(s string) ([]string, error) {
+	var (
+		out   []string
+		cur   strings.Builder
+		quote bool
+		inTok bool
+	)
+	for _, r := range s {
+		switch {
+		case r == '"':
+			quote = !quote
+			inTok = true
+		case !quote && (r == ' ' || r == '\t'):
+			if inTok {
+				out = append(out, cur.String())
+				cur.Reset()
+				inTok = false
+			}
+		default:
+			cur.WriteRune(r)
+			inTok = true
+		}
+	}
+	if quote {
+		return nil, fmt.Errorf("unterminated quote")
+	}
+	if inTok {
+		out = append(out, cur.String())
+	}
+	return out, nil
+}
+
+// ParseDuration is time.ParseDuration plus a d (24h) unit.
+func ParseDuration(s string) (time.Duration, error) {
+	if n, ok := strings.CutSuffix(s, "d"); ok {
+		days, err := strconv.ParseFloat(n, 64)
+		if err != nil {
+			return 0, fmt.Errorf("bad duration %q", s)
+		}
+		return time.Duration(days * float64(24*time.Hour)), nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("bad duration %q", s)
+	}
+	return d, nil
+}
+
+// And returns a filter that matches only what both f and g match.
+func (f Filter) And(g Filter) Filter {
+	out := Filter{expr: strings.TrimSpace(f.expr + " " + g.expr)}
+	out.terms = append(append(out.terms, f.terms...), g.terms...)
+	return out
+}
+
+// Empty reports whether the filter has no terms.
+func (f Filter) Empty() bool { return len(f.terms) == 0 }
+
+func (f Filter) String() string { return f.expr }
+
+// Match reports whether r satisfies every term, relative to now.
+func (f Filter) Match(r Record, now time.Time) bool {
+	for _, t := range f.terms {
+		if t.match(r, now) == t.negate {
+			return false
+		}
+	}
+	return true
+}
+
+func (t term) match(r Record, now time.Time) bool {
+	switch t.field {
+	case "":
+		for _, v := range r.Fields {
+			if t.re.MatchString(v) {
+				return true
+			}
+		}
+		return false
+	case "since":
+		// a zero At counts as infinitely old
+		return !r.At.Before(now.Add(-t.since))
+	}
+	v, ok := r.Fields[t.field]
+	if !ok {
+		// not applicable to this record: never filters it out
+		return !t.negate
+	}
+	if t.re != nil {
+		return t.re.MatchString(v)
+	}
+	v = strings.ToLower(v)
+	for _, want := range t.values {
+		if v == want {
+			return true
+		}
+	}
+	return false
+}
+
+// Highlight returns a regexp matching the positive bare words and field
+// regexes, for picking out matches in rendered text. It is nil when there
+// is nothing to highlight.
+func (f Filter) Highlight() *regexp.Regexp {
+	pats := []string{}
+	for _, t := range f.terms {
+		if t.negate || t.re == nil {
+			continue
+		}
+		pats = append(pats, "(?:"+strings.TrimPrefix(t.re.String(), "(?i)")+")")
+	}
+	if len(pats) == 0 {
+		return nil
+	}
+	re, err := regexp.Compile("(?i)" + strings.Join(pats, "|"))
+	if err != nil {
+		return nil
+	}
+	return re
+}
+
+// In matches records whose field equals one of values. With no values it
+// matches no record that has the field, which is what an all-off toggle
+// group needs.
+func In(field string, values ...string) Filter {
+	t := term{field: strings.ToLower(field)}
+	for _, v := range values {
+		t.values = append(t.values, strings.ToLower(v))
+	}
+	return Filter{expr: t.field + ":" + strings.Join(values, ","), terms: []term{t}}
+}
+
+// Toggles are the UI's filter switches as queries, so that commands
+// outside the UI filter the same way.
+type Toggles struct {
+	// Window is how far back Recent reaches, e.g. 24h.
+	Window          string
+	Recent          bool
+	HideRunSessions bool
+	// PrimaryModel, when set, keeps only sessions on that model.
+	PrimaryModel string
+	// Levels and Sources are the task levels and sources shown.
+	Levels  []string
+	Sources []string
+}
+
+// Sessions is the toggles' filter for sessions.
+func (t Toggles) Sessions() Filter {
+	var f Filter
+	if t.Recent {
+		f = f.And(MustParse("since:" + t.Window))
+	}
+	if t.HideRunSessions {
+		f = f.And(MustParse("-key:~:run:"))
+	}
+	if t.PrimaryModel != "" {
+		f = f.And(In("model", t.PrimaryModel))
+	}
+	return f
+}
+
+// Tasks is the toggles' filter for tasks.
+func (t Toggles) Tasks() Filter {
+	return In("level", t.Levels...).And(In("source", t.Sources...))
+}
@@ -0,0 +1,104 @@
+package query
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cl4wb0rg/clawtop/internal/openclaw"
+)
+
+func TestParse_Errors(t *testing.T) {
+	for _, expr := range []string{"since:soon", "key:~(", "label:", `label:"open`} {
+		if _, err := Parse(expr); err == nil {
+			t.Fatalf("Parse(%q): expected error", expr)
+		}
+	}
+}
+
+func TestMatch_Tasks(t *testing.T) {
+	now := time.UnixMilli(1700000000000)
+	tasks := []openclaw.Task{
+		{At: now.Add(-time.Hour), Level: openclaw.LevelError, Source: openclaw.SourceCron, Title: "cron: nightly", Detail: "timeout"},
+		{At: now.Add(-10 * time.Hour), Level: openclaw.LevelError, Source: openclaw.SourceCron, Title: "cron: weekly"},
+		{At: now.Add(-time.Minute), Level: openclaw.LevelInfo, Source: openclaw.SourceTool, Title: "exec"},
+	}
+	cases := []struct {
+		expr string
+		want int
+	}{
+		{"", 3},
+		{"level:error source:cron since:6h", 1},
+		{"level:error,info", 3},
+		{"-source:tool", 2},
+		{"TIMEOUT", 1},
+		{"~week(ly)?", 1},
+		{"title:~^cron:", 2},
+		{"model:gpt-5.2", 3}, // tasks have no model
+	}
+	for _, c := range cases {
+		f, err := Parse(c.expr)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", c.expr, err)
+		}
+		n := 0
+		for _, task := range tasks {
+			if f.Match(TaskRecord(task), now) {
+				n++
+			}
+		}
+		if n != c.want {
+			t.Fatalf("%q matched %d, want %d", c.expr, n, c.want)
+		}
+	}
+}
+
+func TestMatch_Sessions(t *testing.T) {
+	now := time.UnixMilli(1700000000000)
+	s := openclaw.Session{Instance: "staging", Key: "agent:main:cron:abc:run:1", Label: "nightly report", Model: "gpt-5.2", UpdatedAt: now.Add(-2 * 24 * time.Hour)}
+	cases := map[string]bool{
+		"model:GPT-5.2":          true,
+		"model:gpt-5":            false,
+		"key:~cron":              true,
+		"-key:~:run:":            false,
+		`label:"nightly report"`: true,
+		"since:1d":               false,
+		"since:3d":               true,
+		"agent:main level:error": true,
+		"instance:staging":       true,
+		"instance:prod":          false,
+	}
+	for expr, want := range cases {
+		if got := MustParse(expr).Match(SessionRecord(s), now); got != want {
+			t.Fatalf("%q: got %v want %v", expr, got, want)
+		}
+	}
+}
+
+func TestAnd(t *testing.T) {
+	f := MustParse("level:error").And(MustParse("source:cron"))
+	if f.String() != "level:error source:cron" {
+		t.Fatalf("String()=%q", f.String())
+	}
+	if f.Match(TaskRecord(openclaw.Task{Level: openclaw.LevelError, Source: openclaw.SourceTool}), time.Now()) {
+		t.Fatal("expected tool task to be filtered out")
+	}
+}
+
+func TestToggles(t *testing.T) {
+	now := time.UnixMilli(1700000000000)
+	tg := Toggles{Window: "24h", Recent: true, HideRunSessions: true, PrimaryModel: "gpt-5.2", Levels: []string{"error"}, Sources: []string{"cron"}}
+	s := openclaw.Session{Key: "agent:main:main", Model: "gpt-5.2", UpdatedAt: now.Add(-time.Hour)}
+	if !tg.Sessions().Match(SessionRecord(s), now) {
+		t.Fatal("session should match")
+	}
+	s.Key += ":run:1"
+	if tg.Sessions().Match(SessionRecord(s), now) {
+		t.Fatal(":run: session should not match")
+	}
+	if tg.Tasks().Match(TaskRecord(openclaw.Task{Level: openclaw.LevelError, Source: openclaw.SourceTool}), now) {
+		t.Fatal("tool task should not match")
+	}
+	if (Toggles{}).Tasks().Match(TaskRecord(openclaw.Task{Level: openclaw.LevelError}), now) {
+		t.Fatal("no levels on should match no task")
+	}
+}
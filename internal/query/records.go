@@ -0,0 +1,55 @@
+package query
+
+import (
+	"time"
+
+	"github.com/cl4wb0rg/clawtop/internal/openclaw"
+)
+
+func SessionRecord(s openclaw.Session) Record {
+	return Record{At: s.UpdatedAt, Fields: map[string]string{
+		"instance": s.Instance,
+		"key":      s.Key,
+		"label":    s.Label,
+		"model":    s.Model,
+		"provider": s.Provider,
+	}}
+}
+
+func TaskRecord(t openclaw.Task) Record {
+	return Record{At: t.At, Fields: map[string]string{
+		"instance": t.Instance,
+		"level":    string(t.Level),
+		"source":   string(t.Source),
+		"title":    t.Title,
+		"detail":   t.Detail,
+	}}
+}
+
+func SubagentRecord(r openclaw.SubagentRun) Record {
+	return Record{At: r.CreatedAt, Fields: map[string]string{
+		"instance": r.Instance,
+		"key":      r.ChildSessionKey,
+		"label":    r.Label,
+		"model":    r.Model,
+		"task":     r.Task,
+		"status":   r.Status(),
+	}}
+}
+
+// CronRecord uses the last run time for since:, so jobs that never ran
+// are excluded by it.
+func CronRecord(c openclaw.CronJob) Record {
+	var at time.Time
+	if c.LastRun != nil {
+		at = *c.LastRun
+	}
+	return Record{At: at, Fields: map[string]string{
+		"instance": c.Instance,
+		"id":       c.ID,
+		"name":     c.Name,
+		"status":   c.LastStatus,
+		"error":    c.LastError,
+		"schedule": c.Schedule,
+	}}
+}
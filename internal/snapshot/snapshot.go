@@ -0,0 +1,239 @@
+// Package snapshot is clawtop's stable JSON model of everything it reads,
+// for scripts. schema/snapshot.v1.json in the repository describes it;
+// fields are only ever added within a version, and Version changes when
+// one is renamed, retyped or removed.
+package snapshot
+
+import (
+	"time"
+
+	"github.com/cl4wb0rg/clawtop/internal/collect"
+	"github.com/cl4wb0rg/clawtop/internal/config"
+	"github.com/cl4wb0rg/clawtop/internal/host"
+	"github.com/cl4wb0rg/clawtop/internal/query"
+)
+
+// Version is the schema version written to every snapshot.
+const Version = 1
+
+// DefaultInstance names the installation when no profile is active.
+const DefaultInstance = collect.DefaultInstance
+
+type Snapshot struct {
+	Version   int        `json:"version"`
+	At        time.Time  `json:"at"`
+	Host      Host       `json:"host"`
+	Instances []Instance `json:"instances"`
+	Sessions  []Session  `json:"sessions"`
+	Subagents []Subagent `json:"subagents"`
+	Crons     []Cron     `json:"crons"`
+	Tasks     []Task     `json:"tasks"`
+	// Tokens are the token samples, summed over instances.
+	Tokens []TokenSample `json:"tokens"`
+}
+
+type Host struct {
+	CPUPercent        float64 `json:"cpuPercent"`
+	MemUsedBytes      uint64  `json:"memUsedBytes"`
+	MemTotalBytes     uint64  `json:"memTotalBytes"`
+	SwapUsedBytes     uint64  `json:"swapUsedBytes"`
+	SwapTotalBytes    uint64  `json:"swapTotalBytes"`
+	Load1             float64 `json:"load1"`
+	Load5             float64 `json:"load5"`
+	Load15            float64 `json:"load15"`
+	MajorFaultsPerSec float64 `json:"majorFaultsPerSec"`
+	// Pressure is absent when the kernel has PSI disabled.
+	Pressure     *Pressure     `json:"pressure,omitempty"`
+	Temperatures []Temperature `json:"temperatures"`
+	// CPUFreqMHz and CPUMaxFreqMHz are 0 without cpufreq.
+	CPUFreqMHz    float64 `json:"cpuFreqMHz"`
+	CPUMaxFreqMHz float64 `json:"cpuMaxFreqMHz"`
+}
+
+type Pressure struct {
+	CPU    PressureStat `json:"cpu"`
+	Memory PressureStat `json:"memory"`
+	IO     PressureStat `json:"io"`
+}
+
+// PressureStat is percent of wall time stalled over 10s, 60s and 300s.
+type PressureStat struct {
+	Some10  float64 `json:"some10"`
+	Some60  float64 `json:"some60"`
+	Some300 float64 `json:"some300"`
+	Full10  float64 `json:"full10"`
+	Full60  float64 `json:"full60"`
+	Full300 float64 `json:"full300"`
+}
+
+type Temperature struct {
+	Chip    string  `json:"chip"`
+	Label   string  `json:"label"`
+	Celsius float64 `json:"celsius"`
+	// CritCelsius is 0 when the driver has no critical trip point.
+	CritCelsius float64 `json:"critCelsius"`
+}
+
+// Instance is the health of one installation and of each file read
+// from it.
+type Instance struct {
+	Name string `json:"name"`
+	Root string `json:"root"`
+	OK   bool   `json:"ok"`
+	// Error is why the instance could not be read, when OK is false.
+	Error   string   `json:"error,omitempty"`
+	Sources []Source `json:"sources"`
+}
+
+type Source struct {
+	Name string `json:"name"`
+	Path string `json:"path"`
+	// Status is ok, missing or error.
+	Status     string     `json:"status"`
+	Error      string     `json:"error,omitempty"`
+	ModifiedAt *time.Time `json:"modifiedAt,omitempty"`
+}
+
+type Session struct {
+	Instance     string    `json:"instance"`
+	Key          string    `json:"key"`
+	Label        string    `json:"label"`
+	Model        string    `json:"model"`
+	Provider     string    `json:"provider"`
+	UpdatedAt    time.Time `json:"updatedAt"`
+	InputTokens  int64     `json:"inputTokens"`
+	OutputTokens int64     `json:"outputTokens"`
+	TotalTokens  int64     `json:"totalTokens"`
+}
+
+type Subagent struct {
+	Instance   string     `json:"instance"`
+	RunID      string     `json:"runId"`
+	SessionKey string     `json:"sessionKey"`
+	Label      string     `json:"label"`
+	Task       string     `json:"task"`
+	Model      string     `json:"model"`
+	Status     string     `json:"status"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	StartedAt  *time.Time `json:"startedAt"`
+	FinishedAt *time.Time `json:"finishedAt"`
+}
+
+type Cron struct {
+	Instance   string     `json:"instance"`
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Enabled    bool       `json:"enabled"`
+	Schedule   string     `json:"schedule"`
+	TZ         string     `json:"tz"`
+	NextRunAt  *time.Time `json:"nextRunAt"`
+	LastRunAt  *time.Time `json:"lastRunAt"`
+	LastStatus string     `json:"lastStatus"`
+	LastError  string     `json:"lastError"`
+}
+
+type Task struct {
+	Instance string    `json:"instance"`
+	At       time.Time `json:"at"`
+	Level    string    `json:"level"`
+	Source   string    `json:"source"`
+	Title    string    `json:"title"`
+	Detail   string    `json:"detail"`
+}
+
+type TokenSample struct {
+	At            time.Time `json:"at"`
+	OpenClawTotal int64     `json:"openclawTotal"`
+	ClaudeCostUSD float64   `json:"claudeCostUSD"`
+}
+
+// Build converts what collect read into a Snapshot. Records that don't
+// match f are left out; instances and sources are always listed.
+func Build(at time.Time, h host.HostMetrics, ds []collect.Data, limits config.Limits, f query.Filter) Snapshot {
+	s := Snapshot{
+		Version:   Version,
+		At:        at,
+		Host:      hostOf(h),
+		Instances: make([]Instance, 0, len(ds)),
+		Sessions:  []Session{},
+		Subagents: []Subagent{},
+		Crons:     []Cron{},
+		Tasks:     []Task{},
+		Tokens:    []TokenSample{},
+	}
+	for _, d := range ds {
+		s.Instances = append(s.Instances, instanceOf(d))
+	}
+	all := collect.Merge(ds, limits)
+	for _, r := range all.Sessions {
+		if f.Match(query.SessionRecord(r), at) {
+			s.Sessions = append(s.Sessions, Session{collect.InstanceName(r.Instance), r.Key, r.Label, r.Model, r.Provider, r.UpdatedAt, r.InputTokens, r.OutputTokens, r.TotalTokens})
+		}
+	}
+	for _, r := range all.Subagents {
+		if f.Match(query.SubagentRecord(r), at) {
+			s.Subagents = append(s.Subagents, Subagent{collect.InstanceName(r.Instance), r.RunID, r.ChildSessionKey, r.Label, r.Task, r.Model, r.Status(), r.CreatedAt, r.StartedAt, r.FinishedAt})
+		}
+	}
+	for _, r := range all.Crons {
+		if f.Match(query.CronRecord(r), at) {
+			s.Crons = append(s.Crons, Cron{collect.InstanceName(r.Instance), r.ID, r.Name, r.Enabled, r.Schedule, r.TZ, r.NextRun, r.LastRun, r.LastStatus, r.LastError})
+		}
+	}
+	for _, r := range all.Tasks {
+		if f.Match(query.TaskRecord(r), at) {
+			s.Tasks = append(s.Tasks, Task{collect.InstanceName(r.Instance), r.At, string(r.Level), string(r.Source), r.Title, r.Detail})
+		}
+	}
+	for _, t := range all.TokenSamples {
+		s.Tokens = append(s.Tokens, TokenSample{t.At, t.OpenClawTotal, t.ClaudeCostUSD})
+	}
+	return s
+}
+
+func instanceOf(d collect.Data) Instance {
+	in := Instance{Name: collect.InstanceName(d.Instance), Root: d.Root, OK: d.Err == nil, Sources: []Source{}}
+	if d.Err != nil {
+		in.Error = d.Err.Error()
+	}
+	for _, src := range d.Sources {
+		out := Source{Name: src.Name, Path: src.Path, Status: src.Status()}
+		if src.Err != nil {
+			out.Error = src.Err.Error()
+		}
+		if !src.ModTime.IsZero() {
+			t := src.ModTime
+			out.ModifiedAt = &t
+		}
+		in.Sources = append(in.Sources, out)
+	}
+	return in
+}
+
+func hostOf(m host.HostMetrics) Host {
+	h := Host{
+		CPUPercent:        m.CPUPercent,
+		MemUsedBytes:      m.MemUsedBytes,
+		MemTotalBytes:     m.MemTotalBytes,
+		SwapUsedBytes:     m.SwapUsedBytes,
+		SwapTotalBytes:    m.SwapTotalBytes,
+		Load1:             m.Load1,
+		Load5:             m.Load5,
+		Load15:            m.Load15,
+		MajorFaultsPerSec: m.MajFaultsPerSec,
+		Temperatures:      []Temperature{},
+		CPUFreqMHz:        m.Sensors.CPUFreqMHz,
+		CPUMaxFreqMHz:     m.Sensors.CPUMaxFreqMHz,
+	}
+	if m.HasPSI {
+		h.Pressure = &Pressure{pressureOf(m.PSICPU), pressureOf(m.PSIMemory), pressureOf(m.PSIIO)}
+	}
+	for _, t := range m.Sensors.Temps {
+		h.Temperatures = append(h.Temperatures, Temperature{t.Chip, t.Label, t.Celsius, t.CritC})
+	}
+	return h
+}
+
+func pressureOf(p host.Pressure) PressureStat {
+	return PressureStat{p.SomeAvg10, p.SomeAvg60, p.SomeAvg300, p.FullAvg10, p.FullAvg60, p.FullAvg300}
+}
@@ -0,0 +1,180 @@
+package snapshot
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cl4wb0rg/clawtop/internal/collect"
+	"github.com/cl4wb0rg/clawtop/internal/config"
+	"github.com/cl4wb0rg/clawtop/internal/host"
+	"github.com/cl4wb0rg/clawtop/internal/openclaw"
+	"github.com/cl4wb0rg/clawtop/internal/query"
+)
+
+func sample() Snapshot {
+	at := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	h := host.HostMetrics{CPUPercent: 12.5, MemUsedBytes: 1 << 30, MemTotalBytes: 4 << 30, HasPSI: true,
+		Sensors: host.Sensors{Temps: []host.Temp{{Chip: "coretemp", Label: "Package id 0", Celsius: 51, CritC: 100}}}}
+	ds := []collect.Data{
+		{
+			Instance: "prod", Root: "/srv/prod",
+			Sessions:     []openclaw.Session{{Instance: "prod", Key: "agent:main:main", UpdatedAt: at}},
+			Subagents:    []openclaw.SubagentRun{{Instance: "prod", RunID: "r1", CreatedAt: at, FinishedAt: &at}},
+			Crons:        []openclaw.CronJob{{Instance: "prod", ID: "c1", LastRun: &at, LastStatus: "error", LastError: "timeout"}},
+			Tasks:        []openclaw.Task{{Instance: "prod", At: at, Level: openclaw.LevelError, Source: openclaw.SourceCron}},
+			TokenSamples: []openclaw.TokenSample{{At: at, OpenClawTotal: 9000, ClaudeCostUSD: 1.5}},
+			Sources: []collect.Source{
+				{Name: "sessions", Path: "/srv/prod/agents/main/sessions/sessions.json", ModTime: at},
+				{Name: "tokens", Path: "/srv/prod/workspace/dashboard/metrics/tokens.jsonl", Err: os.ErrNotExist},
+			},
+		},
+		{Instance: "staging", Err: errors.New("openclaw root not found: /srv/staging")},
+	}
+	return Build(at, h, ds, config.Default().Limits, query.Filter{})
+}
+
+func TestBuild(t *testing.T) {
+	s := sample()
+	if s.Version != Version || len(s.Instances) != 2 || !s.Instances[0].OK || s.Instances[1].OK {
+		t.Fatalf("%+v", s.Instances)
+	}
+	if st := s.Instances[0].Sources[1].Status; st != "missing" {
+		t.Fatalf("tokens status %q", st)
+	}
+	if s.Subagents[0].Status != "done" || s.Crons[0].LastStatus != "error" || len(s.Tokens) != 1 {
+		t.Fatalf("%+v", s)
+	}
+	f := query.MustParse("level:warn")
+	if got := Build(s.At, host.HostMetrics{}, []collect.Data{{Tasks: []openclaw.Task{{Level: openclaw.LevelError}}}}, config.Default().Limits, f); len(got.Tasks) != 0 {
+		t.Fatalf("filter not applied: %+v", got.Tasks)
+	} else if got.Instances[0].Name != DefaultInstance {
+		t.Fatalf("unnamed instance %q", got.Instances[0].Name)
+	}
+}
+
+// TestSchema checks a snapshot with every optional field present against
+// schema/snapshot.v1.json, so the two cannot drift apart.
+func TestSchema(t *testing.T) {
+	b, err := os.ReadFile("../../schema/snapshot.v1.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var schema map[string]any
+	if err := json.Unmarshal(b, &schema); err != nil {
+		t.Fatal(err)
+	}
+	out, err := json.Marshal(sample())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc any
+	if err := json.Unmarshal(out, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if err := validate(schema, schema, doc, "$"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// validate checks v against the subset of JSON Schema the schema file
+// uses: $ref, type, const, enum, properties, required,
+// additionalProperties and items.
+func validate(root, s map[string]any, v any, path string) error {
+	if ref, ok := s["$ref"].(string); ok {
+		def := root["$defs"].(map[string]any)[strings.TrimPrefix(ref, "#/$defs/")]
+		if def == nil {
+			return fmt.Errorf("%s: unknown $ref %s", path, ref)
+		}
+		return validate(root, def.(map[string]any), v, path)
+	}
+	if c, ok := s["const"]; ok && c != v {
+		return fmt.Errorf("%s: %v is not %v", path, v, c)
+	}
+	if enum, ok := s["enum"].([]any); ok {
+		found := false
+		for _, e := range enum {
+			found = found || e == v
+		}
+		if !found {
+			return fmt.Errorf("%s: %v not in %v", path, v, enum)
+		}
+	}
+	if typ, ok := s["type"]; ok {
+		types := []any{typ}
+		if ts, ok := typ.([]any); ok {
+			types = ts
+		}
+		match := false
+		for _, t := range types {
+			match = match || hasType(v, t.(string))
+		}
+		if !match {
+			return fmt.Errorf("%s: %v is not %v", path, v, typ)
+		}
+	}
+	switch v := v.(type) {
+	case map[string]any:
+		props, _ := s["properties"].(map[string]any)
+		required, _ := s["required"].([]any)
+		for _, r := range required {
+			if _, ok := v[r.(string)]; !ok {
+				return fmt.Errorf("%s: missing %s", path, r)
+			}
+		}
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			ps, ok := props[k]
+			if !ok {
+				return fmt.Errorf("%s: %s is not in the schema", path, k)
+			}
+			if err := validate(root, ps.(map[string]any), v[k], path+"."+k); err != nil {
+				return err
+			}
+		}
+	case []any:
+		if items, ok := s["items"].(map[string]any); ok {
+			for i, e := range v {
+				if err := validate(root, items, e, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func hasType(v any, t string) bool {
+	switch t {
+	case "object":
+		_, ok := v.(map[string]any)
+		return ok
+	case "array":
+		_, ok := v.([]any)
+		return ok
+	case "string":
+		_, ok := v.(string)
+		return ok
+	case "number":
+		_, ok := v.(float64)
+		return ok
+	case "integer":
+		f, ok := v.(float64)
+		return ok && f == float64(int64(f))
+	case "boolean":
+		_, ok := v.(bool)
+		return ok
+	case "null":
+		return v == nil
+	}
+	return false
+}
@@ -0,0 +1,92 @@
+// Package state saves the UI settings clawtop restores on the next run:
+// filter toggles, refresh rate, view, sort order and searches.
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// State is the saved UI. Names (views, panels, sort keys, levels and
+// sources) are the lower-case ones the UI shows, so the file stays
+// readable and survives reordering of the UI's enums.
+type State struct {
+	View    string `json:"view,omitempty"`
+	Focus   string `json:"focus,omitempty"`
+	Refresh string `json:"refresh,omitempty"`
+
+	// Profile is the profile the toggles and searches below were saved
+	// under; under another profile they are not restored.
+	Profile string `json:"profile,omitempty"`
+
+	Filter24h        bool `json:"filter24h"`
+	HideRunSessions  bool `json:"hideRunSessions"`
+	PrimaryModelOnly bool `json:"primaryModelOnly"`
+
+	Levels  map[string]bool `json:"levels,omitempty"`
+	Sources map[string]bool `json:"sources,omitempty"`
+
+	SortKey string `json:"sortKey,omitempty"`
+	SortAsc bool   `json:"sortAsc,omitempty"`
+
+	// Searches maps panel names to their / query.
+	Searches map[string]string `json:"searches,omitempty"`
+}
+
+// DefaultPath is $XDG_STATE_HOME/clawtop/state.json, falling back to
+// ~/.local/state when XDG_STATE_HOME is unset.
+func DefaultPath() string {
+	dir := os.Getenv("XDG_STATE_HOME")
+	if dir == "" {
+		h, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(h, ".local", "state")
+	}
+	return filepath.Join(dir, "clawtop", "state.json")
+}
+
+// Load reads the state at path. A missing file is not an error: it
+// returns nil, meaning "use the defaults".
+func Load(path string) (*State, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var s State
+	if err := json.Unmarshal(b, &s); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &s, nil
+}
+
+// Save writes s to path, creating the directory. The file is replaced
+// atomically so a crash never leaves half a state behind.
+func Save(path string, s State) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".state-*.json")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(append(b, '\n')); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,40 @@
+package state
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestLoad_Missing(t *testing.T) {
+	s, err := Load(filepath.Join(t.TempDir(), "state.json"))
+	if err != nil || s != nil {
+		t.Fatalf("s=%v err=%v", s, err)
+	}
+}
+
+func TestSaveLoad(t *testing.T) {
+	p := filepath.Join(t.TempDir(), "clawtop", "state.json")
+	in := State{
+		View:      "tasks",
+		Refresh:   "5s",
+		Filter24h: true,
+		Levels:    map[string]bool{"debug": true},
+		SortKey:   "total",
+		Searches:  map[string]string{"tasks": "level:error"},
+	}
+	if err := Save(p, in); err != nil {
+		t.Fatal(err)
+	}
+	out, err := Load(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.View != "tasks" || out.Refresh != "5s" || !out.Filter24h || out.HideRunSessions ||
+		!out.Levels["debug"] || out.SortKey != "total" || out.Searches["tasks"] != "level:error" {
+		t.Fatalf("round trip: %#v", out)
+	}
+	matches, _ := filepath.Glob(filepath.Join(filepath.Dir(p), ".state-*"))
+	if len(matches) != 0 {
+		t.Fatalf("temp files left: %v", matches)
+	}
+}
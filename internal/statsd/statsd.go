@@ -0,0 +1,208 @@
+// Package statsd pushes what clawtop reads to a StatsD or DogStatsD agent
+// over UDP, once per refresh.
+//
+// Gauges carry the current state. Counters carry what changed since the
+// previous push of the same instance: tokens used per session, cron runs
+// by outcome and subagent runs that finished. An instance's first push
+// only records where things stand, so a restart (or switching to another
+// profile) doesn't count the whole history again.
+package statsd
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cl4wb0rg/clawtop/internal/collect"
+	"github.com/cl4wb0rg/clawtop/internal/config"
+	"github.com/cl4wb0rg/clawtop/internal/host"
+	"github.com/cl4wb0rg/clawtop/internal/openclaw"
+)
+
+// maxPacket keeps datagrams within a typical Ethernet MTU.
+const maxPacket = 1432
+
+// Exporter pushes to one agent. It is safe for concurrent use; a nil
+// *Exporter pushes nothing.
+type Exporter struct {
+	conn   net.Conn
+	prefix string
+	tags   []string
+	plain  bool
+
+	mu sync.Mutex
+	// seen is what the previous push of each instance saw; instances
+	// missing from a push keep theirs
+	seen map[string]seen
+}
+
+// seen is one instance's counters, by session key, cron job id and run id.
+type seen struct {
+	tokens  map[string]int64
+	cronRun map[string]time.Time
+	done    map[string]bool
+}
+
+// New connects to c.Addr; it returns nil when c.Addr is empty.
+func New(c config.StatsD) (*Exporter, error) {
+	if c.Addr == "" {
+		return nil, nil
+	}
+	conn, err := net.Dial("udp", c.Addr)
+	if err != nil {
+		return nil, fmt.Errorf("statsd: %w", err)
+	}
+	e := &Exporter{conn: conn, prefix: c.Prefix, plain: c.Plain, seen: map[string]seen{}}
+	for k, v := range c.Tags {
+		e.tags = append(e.tags, tag(k, v))
+	}
+	sort.Strings(e.tags)
+	return e, nil
+}
+
+// Close closes the connection.
+func (e *Exporter) Close() error {
+	if e == nil {
+		return nil
+	}
+	return e.conn.Close()
+}
+
+// Push sends the host metrics and every instance in ds. Filters are not
+// applied.
+func (e *Exporter) Push(h host.HostMetrics, ds []collect.Data) error {
+	if e == nil {
+		return nil
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	p := &packets{e: e}
+	p.gauge("host.cpu_percent", h.CPUPercent)
+	p.gauge("host.mem_used_bytes", float64(h.MemUsedBytes))
+	p.gauge("host.mem_total_bytes", float64(h.MemTotalBytes))
+	p.gauge("host.swap_used_bytes", float64(h.SwapUsedBytes))
+	p.gauge("host.load1", h.Load1)
+
+	for _, d := range ds {
+		inst := collect.InstanceName(d.Instance)
+		it := tag("instance", inst)
+		up := 0.0
+		if d.Err == nil {
+			up = 1
+		}
+		p.gauge("up", up, it)
+		if d.Err != nil {
+			continue
+		}
+		prev, primed := e.seen[inst]
+		cur := seen{tokens: map[string]int64{}, cronRun: map[string]time.Time{}, done: map[string]bool{}}
+		e.seen[inst] = cur
+
+		type am struct{ agent, model string }
+		counts := map[am]int{}
+		for _, s := range d.Sessions {
+			agent := openclaw.AgentOf(s.Key)
+			counts[am{agent, s.Model}]++
+			cur.tokens[s.Key] = s.TotalTokens
+			// a session new since the last push counts from zero
+			if was := prev.tokens[s.Key]; primed && s.TotalTokens > was {
+				p.count("session.tokens", s.TotalTokens-was, it, tag("agent", agent), tag("model", s.Model))
+			}
+		}
+		keys := make([]am, 0, len(counts))
+		for k := range counts {
+			keys = append(keys, k)
+		}
+		sort.Slice(keys, func(i, j int) bool { return keys[i].agent+"\x00"+keys[i].model < keys[j].agent+"\x00"+keys[j].model })
+		for _, k := range keys {
+			p.gauge("sessions", float64(counts[k]), it, tag("agent", k.agent), tag("model", k.model))
+		}
+
+		running, queued := 0, 0
+		for _, r := range d.Subagents {
+			switch r.Status() {
+			case "running":
+				running++
+			case "queued":
+				queued++
+			case "done":
+				cur.done[r.RunID] = true
+				if primed && !prev.done[r.RunID] {
+					p.count("subagent.completed", 1, it, tag("agent", openclaw.AgentOf(r.ChildSessionKey)), tag("model", r.Model))
+				}
+			}
+		}
+		p.gauge("subagents.running", float64(running), it)
+		p.gauge("subagents.queued", float64(queued), it)
+
+		for _, c := range d.Crons {
+			jt := tag("job", c.Name)
+			p.gauge("cron.consecutive_failures", float64(c.ConsecutiveErrors), it, jt)
+			if c.LastRun == nil {
+				continue
+			}
+			cur.cronRun[c.ID] = *c.LastRun
+			if was, ok := prev.cronRun[c.ID]; primed && (!ok || c.LastRun.After(was)) {
+				status := c.LastStatus
+				if status == "" {
+					status = "unknown"
+				}
+				p.count("cron.runs", 1, it, jt, tag("status", status))
+			}
+		}
+	}
+	return p.flush()
+}
+
+// tag is a DogStatsD key:value tag; characters that delimit tags or
+// fields become underscores.
+func tag(k, v string) string {
+	clean := strings.NewReplacer(",", "_", "|", "_", "#", "_", "\n", "_", " ", "_")
+	return clean.Replace(k) + ":" + clean.Replace(v)
+}
+
+// packets batches metric lines into datagrams of at most maxPacket bytes.
+type packets struct {
+	e   *Exporter
+	buf []byte
+	err error
+}
+
+func (p *packets) gauge(name string, v float64, tags ...string) {
+	p.line(name, strconv.FormatFloat(v, 'f', -1, 64), "g", tags)
+}
+
+func (p *packets) count(name string, n int64, tags ...string) {
+	p.line(name, strconv.FormatInt(n, 10), "c", tags)
+}
+
+func (p *packets) line(name, value, typ string, tags []string) {
+	ln := p.e.prefix + name + ":" + value + "|" + typ
+	if !p.e.plain {
+		if all := append(append([]string{}, p.e.tags...), tags...); len(all) > 0 {
+			ln += "|#" + strings.Join(all, ",")
+		}
+	}
+	if len(p.buf) > 0 && len(p.buf)+1+len(ln) > maxPacket {
+		p.flush()
+	}
+	if len(p.buf) > 0 {
+		p.buf = append(p.buf, '\n')
+	}
+	p.buf = append(p.buf, ln...)
+}
+
+// flush sends what is buffered and returns the first send error.
+func (p *packets) flush() error {
+	if len(p.buf) > 0 {
+		if _, err := p.e.conn.Write(p.buf); err != nil && p.err == nil {
+			p.err = err
+		}
+		p.buf = p.buf[:0]
+	}
+	return p.err
+}
@@ -0,0 +1,191 @@
+package statsd
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cl4wb0rg/clawtop/internal/collect"
+	"github.com/cl4wb0rg/clawtop/internal/config"
+	"github.com/cl4wb0rg/clawtop/internal/host"
+	"github.com/cl4wb0rg/clawtop/internal/openclaw"
+)
+
+// listen starts a local UDP listener and returns its address and a
+// function that reads every line received within a short wait.
+func listen(t *testing.T) (string, func() []string) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn.LocalAddr().String(), func() []string {
+		var lines []string
+		buf := make([]byte, 65536)
+		for {
+			conn.SetReadDeadline(time.Now().Add(200 * time.Millisecond))
+			n, _, err := conn.ReadFrom(buf)
+			if err != nil {
+				return lines
+			}
+			if n > maxPacket {
+				t.Fatalf("datagram of %d bytes", n)
+			}
+			lines = append(lines, strings.Split(string(buf[:n]), "\n")...)
+		}
+	}
+}
+
+func has(lines []string, want string) bool {
+	for _, ln := range lines {
+		if ln == want {
+			return true
+		}
+	}
+	return false
+}
+
+func TestPush(t *testing.T) {
+	addr, read := listen(t)
+	e, err := New(config.StatsD{Addr: addr, Prefix: "clawtop.", Tags: map[string]string{"env": "prod"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer e.Close()
+
+	t0 := time.Unix(1700000000, 0)
+	d := collect.Data{
+		Sessions:  []openclaw.Session{{Key: "agent:main:main", Model: "gpt-5.2", TotalTokens: 100}},
+		Subagents: []openclaw.SubagentRun{{RunID: "r1", ChildSessionKey: "agent:main:subagent:x", Model: "opus", StartedAt: &t0}},
+		Crons:     []openclaw.CronJob{{ID: "c1", Name: "backup job", LastRun: &t0, LastStatus: "ok"}},
+	}
+	h := host.HostMetrics{CPUPercent: 12.5, MemUsedBytes: 1024}
+	if err := e.Push(h, []collect.Data{d}); err != nil {
+		t.Fatal(err)
+	}
+	lines := read()
+	for _, want := range []string{
+		"clawtop.host.cpu_percent:12.5|g|#env:prod",
+		"clawtop.host.mem_used_bytes:1024|g|#env:prod",
+		"clawtop.up:1|g|#env:prod,instance:default",
+		"clawtop.sessions:1|g|#env:prod,instance:default,agent:main,model:gpt-5.2",
+		"clawtop.subagents.running:1|g|#env:prod,instance:default",
+		"clawtop.cron.consecutive_failures:0|g|#env:prod,instance:default,job:backup_job",
+	} {
+		if !has(lines, want) {
+			t.Fatalf("missing %q in\n%s", want, strings.Join(lines, "\n"))
+		}
+	}
+	for _, ln := range lines {
+		if strings.Contains(ln, "|c") {
+			t.Fatalf("first push sent a counter: %s", ln)
+		}
+	}
+
+	t1 := t0.Add(time.Hour)
+	d.Sessions[0].TotalTokens = 250
+	d.Sessions = append(d.Sessions, openclaw.Session{Key: "agent:ops:main", Model: "opus", TotalTokens: 7})
+	d.Subagents[0].FinishedAt = &t1
+	d.Crons[0].LastRun, d.Crons[0].LastStatus, d.Crons[0].ConsecutiveErrors = &t1, "error", 1
+	if err := e.Push(h, []collect.Data{d}); err != nil {
+		t.Fatal(err)
+	}
+	lines = read()
+	for _, want := range []string{
+		"clawtop.session.tokens:150|c|#env:prod,instance:default,agent:main,model:gpt-5.2",
+		"clawtop.session.tokens:7|c|#env:prod,instance:default,agent:ops,model:opus",
+		"clawtop.subagent.completed:1|c|#env:prod,instance:default,agent:main,model:opus",
+		"clawtop.cron.runs:1|c|#env:prod,instance:default,job:backup_job,status:error",
+		"clawtop.cron.consecutive_failures:1|g|#env:prod,instance:default,job:backup_job",
+	} {
+		if !has(lines, want) {
+			t.Fatalf("missing %q in\n%s", want, strings.Join(lines, "\n"))
+		}
+	}
+
+	// nothing changed: no counters
+	if err := e.Push(h, []collect.Data{d}); err != nil {
+		t.Fatal(err)
+	}
+	for _, ln := range read() {
+		if strings.Contains(ln, "|c") {
+			t.Fatalf("unchanged push sent a counter: %s", ln)
+		}
+	}
+}
+
+func TestPushSwitchingInstances(t *testing.T) {
+	addr, read := listen(t)
+	e, err := New(config.StatsD{Addr: addr, Prefix: "clawtop."})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer e.Close()
+
+	t0 := time.Unix(1700000000, 0)
+	prod := collect.Data{
+		Instance:  "prod",
+		Sessions:  []openclaw.Session{{Key: "agent:main:main", Model: "opus", TotalTokens: 10000}},
+		Subagents: []openclaw.SubagentRun{{RunID: "p1", StartedAt: &t0, FinishedAt: &t0}},
+		Crons:     []openclaw.CronJob{{ID: "c1", Name: "nightly", LastRun: &t0, LastStatus: "ok"}},
+	}
+	staging := collect.Data{
+		Instance: "staging",
+		Sessions: []openclaw.Session{{Key: "agent:main:main", Model: "opus", TotalTokens: 500}},
+		Crons:    []openclaw.CronJob{{ID: "c1", Name: "nightly", LastRun: &t0, LastStatus: "ok"}},
+	}
+	// the UI switching profile back and forth pushes one at a time; each
+	// instance's first push is only a baseline
+	for i, d := range []collect.Data{prod, staging, prod, staging} {
+		if err := e.Push(host.HostMetrics{}, []collect.Data{d}); err != nil {
+			t.Fatal(err)
+		}
+		for _, ln := range read() {
+			if strings.Contains(ln, "|c") {
+				t.Fatalf("push %d (%s) sent a counter: %s", i, d.Instance, ln)
+			}
+		}
+	}
+
+	prod.Sessions[0].TotalTokens = 10040
+	if err := e.Push(host.HostMetrics{}, []collect.Data{prod}); err != nil {
+		t.Fatal(err)
+	}
+	if lines := read(); !has(lines, "clawtop.session.tokens:40|c|#instance:prod,agent:main,model:opus") {
+		t.Fatalf("missing prod's own delta in\n%s", strings.Join(lines, "\n"))
+	}
+}
+
+func TestPlainAndBatching(t *testing.T) {
+	addr, read := listen(t)
+	e, err := New(config.StatsD{Addr: addr, Prefix: "oc.", Plain: true, Tags: map[string]string{"env": "prod"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer e.Close()
+	var d collect.Data
+	for i := 0; i < 200; i++ {
+		d.Crons = append(d.Crons, openclaw.CronJob{ID: string(rune('a' + i%26)), Name: strings.Repeat("x", i)})
+	}
+	if err := e.Push(host.HostMetrics{}, []collect.Data{d}); err != nil {
+		t.Fatal(err)
+	}
+	lines := read()
+	if !has(lines, "oc.cron.consecutive_failures:0|g") || !has(lines, "oc.host.load1:0|g") {
+		t.Fatalf("plain lines missing:\n%s", strings.Join(lines, "\n"))
+	}
+	if n := len(lines); n != 5+1+2+200 {
+		t.Fatalf("got %d lines", n)
+	}
+}
+
+func TestNilExporter(t *testing.T) {
+	e, err := New(config.StatsD{})
+	if e != nil || err != nil {
+		t.Fatalf("%v %v", e, err)
+	}
+	if err := e.Push(host.HostMetrics{}, nil); err != nil {
+		t.Fatal(err)
+	}
+}
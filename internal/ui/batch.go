@@ -0,0 +1,116 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/cl4wb0rg/clawtop/internal/collect"
+	"github.com/cl4wb0rg/clawtop/internal/openclaw"
+)
+
+// Batch renders the Overview panels as plain text, one snapshot per Next,
+// for non-interactive output (clawtop -b, --once).
+type Batch struct {
+	m       model
+	w       int
+	sampler collect.Sampler
+	primed  bool
+}
+
+// NewBatch builds a Batch for cfg, w cells wide. Tables are not cut to
+// the terminal height: each shows every row that passes the filters, up
+// to cfg.Limits.OverviewRows where that is set.
+func NewBatch(cfg Config, w int) (*Batch, error) {
+	mm, err := New(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &Batch{m: mm.(model), w: w, sampler: collect.Sampler{SysRoot: cfg.SysRoot}}, nil
+}
+
+// Next reads fresh data and renders it.
+func (b *Batch) Next() string {
+	if !b.primed {
+		b.sampler.Prime()
+		b.primed = true
+	}
+	b.refresh()
+	lines := strings.Split(ansi.Strip(b.m.renderBatch(b.w)), "\n")
+	for i, ln := range lines {
+		lines[i] = strings.TrimRight(ln, " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (b *Batch) refresh() {
+	read := b.m.readCmd(func(at time.Time) collect.HostSample {
+		return collect.HostSample{Metrics: b.sampler.Sample(at)}
+	})
+	mm, _ := b.m.Update(read())
+	b.m = mm.(model)
+}
+
+func (m model) renderBatch(w int) string {
+	header := "clawtop  " + absTime(m.lastUpdate)
+	if name, ok := m.profileName(); ok {
+		header += "  [" + name + "]"
+	}
+	if m.err != nil {
+		header += "  err=" + m.err.Error()
+	}
+	lines := []string{header, m.batchFilters()}
+	if m.cfg.Filter != "" {
+		lines = append(lines, "Filter: "+m.cfg.Filter)
+	}
+	blocks := []string{strings.Join(lines, "\n"), renderHost(m.host), m.renderTokensBlock(false)}
+	titles := [numPanels]string{"Sessions", "Subagents", "Latest Tasks", "Crons"}
+	caps := m.cfg.Limits.OverviewRows
+	for p, limit := range [numPanels]int{caps.Sessions, caps.Subagents, caps.Tasks, caps.Crons} {
+		cols, rows, empty := m.panelRows(panel(p), false)
+		blocks = append(blocks, batchPanel(titles[p], cols, rows, empty, w, limit))
+	}
+	out := strings.Join(blocks, "\n\n")
+	return clipLines(out, w, strings.Count(out, "\n")+1)
+}
+
+// batchFilters spells out the toggles that filtersLine shows as keys.
+func (m model) batchFilters() string {
+	var levels, sources []string
+	for _, l := range []openclaw.TaskLevel{openclaw.LevelError, openclaw.LevelWarn, openclaw.LevelInfo, openclaw.LevelDebug} {
+		if m.levels[l] {
+			levels = append(levels, string(l))
+		}
+	}
+	for _, src := range []openclaw.TaskSource{openclaw.SourceCron, openclaw.SourceSubagent, openclaw.SourceTool} {
+		if m.sources[src] {
+			sources = append(sources, string(src))
+		}
+	}
+	primary := onOff(m.primaryModelOnly)
+	if m.primaryModelOnly && m.primaryModel != "" {
+		primary += " (" + m.primaryModel + ")"
+	}
+	return fmt.Sprintf("Filters: %s=%s  hide:run=%s  primary=%s  levels=%s  sources=%s",
+		m.cfg.Filters.Window, onOff(m.filter24h), onOff(m.hideRunSessions), primary,
+		orDash(strings.Join(levels, ",")), orDash(strings.Join(sources, ",")))
+}
+
+// batchPanel is a titled table of every row, or the first limit of them.
+func batchPanel(title string, cols []column, rows []tableRow, empty string, w, limit int) string {
+	ttl := titleStyle.Render(fmt.Sprintf("%s (%d)", title, len(rows)))
+	if len(rows) == 0 {
+		return ttl + "\n" + dimStyle.Render(empty)
+	}
+	shown := rows
+	if limit > 0 && len(rows) > limit {
+		shown = rows[:limit]
+	}
+	out := ttl + "\n" + table{}.render(cols, shown, w, len(shown)+1, false, nil)
+	if len(shown) < len(rows) {
+		out += "\n" + dimStyle.Render(fmt.Sprintf("… %d more", len(rows)-len(shown)))
+	}
+	return out
+}
@@ -0,0 +1,51 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cl4wb0rg/clawtop/internal/config"
+	"github.com/cl4wb0rg/clawtop/internal/openclaw"
+)
+
+func TestBatch(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "agents", "main", "sessions")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	// distinct updatedAt values so the newest-first order doesn't depend
+	// on map order
+	sessions := `{"agent:main:main": {"label":"Main","model":"gpt-5.2","updatedAt":1700000003000}, "agent:main:cron:1": {"label":"Cron","model":"gpt-5.2","updatedAt":1700000002000}, "agent:main:cron:2": {"label":"Other","model":"gpt-5.2","updatedAt":1700000001000}}`
+	if err := os.WriteFile(filepath.Join(dir, "sessions.json"), []byte(sessions), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	paths, err := openclaw.DiscoverPaths(root, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	limits := config.Default().Limits
+	limits.OverviewRows.Sessions = 2
+	filters := config.Default().Filters
+	filters.Recent = false
+	b, err := NewBatch(Config{Paths: paths, Filters: filters, Limits: limits, Theme: "high-contrast"}, 80)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := b.Next()
+	if strings.Contains(out, "\x1b[") {
+		t.Fatalf("escape codes in batch output:\n%q", out)
+	}
+	for _, want := range []string{"Sessions (3)", "agent:main:main", "… 1 more", "Subagents (0)", "(no runs.json)", "levels=error,warn,info"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("missing %q in:\n%s", want, out)
+		}
+	}
+	for _, ln := range strings.Split(out, "\n") {
+		if textWidth(ln) > 80 || strings.HasSuffix(ln, " ") {
+			t.Fatalf("line too wide or padded: %q", ln)
+		}
+	}
+}
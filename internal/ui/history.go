@@ -0,0 +1,63 @@
+package ui
+
+import (
+	"github.com/cl4wb0rg/clawtop/internal/host"
+)
+
+// hostHistoryCap keeps 10 minutes of samples at the default 2s refresh.
+const hostHistoryCap = 300
+
+// hostHistory is a fixed-size ring buffer of host samples, oldest first.
+type hostHistory struct {
+	buf   []host.HostMetrics
+	start int
+	n     int
+}
+
+func newHostHistory(capacity int) hostHistory {
+	return hostHistory{buf: make([]host.HostMetrics, capacity)}
+}
+
+func (h *hostHistory) push(s host.HostMetrics) {
+	if len(h.buf) == 0 {
+		return
+	}
+	if h.n < len(h.buf) {
+		h.buf[(h.start+h.n)%len(h.buf)] = s
+		h.n++
+		return
+	}
+	h.buf[h.start] = s
+	h.start = (h.start + 1) % len(h.buf)
+}
+
+// last returns up to n of the newest samples in chronological order.
+func (h hostHistory) last(n int) []host.HostMetrics {
+	if n > h.n {
+		n = h.n
+	}
+	out := make([]host.HostMetrics, 0, n)
+	for i := h.n - n; i < h.n; i++ {
+		out = append(out, h.buf[(h.start+i)%len(h.buf)])
+	}
+	return out
+}
+
+// minAvgMax summarises vals; it returns zeros for an empty slice.
+func minAvgMax(vals []float64) (lo, avg, hi float64) {
+	if len(vals) == 0 {
+		return 0, 0, 0
+	}
+	lo, hi = vals[0], vals[0]
+	sum := 0.0
+	for _, v := range vals {
+		if v < lo {
+			lo = v
+		}
+		if v > hi {
+			hi = v
+		}
+		sum += v
+	}
+	return lo, sum / float64(len(vals)), hi
+}
@@ -0,0 +1,33 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/cl4wb0rg/clawtop/internal/host"
+)
+
+func TestHostHistory_Wraps(t *testing.T) {
+	h := newHostHistory(3)
+	for i := 1; i <= 5; i++ {
+		h.push(host.HostMetrics{CPUPercent: float64(i)})
+	}
+	got := h.last(10)
+	if len(got) != 3 {
+		t.Fatalf("len=%d", len(got))
+	}
+	for i, want := range []float64{3, 4, 5} {
+		if got[i].CPUPercent != want {
+			t.Fatalf("got[%d]=%v want %v", i, got[i].CPUPercent, want)
+		}
+	}
+	if got := h.last(2); got[0].CPUPercent != 4 || got[1].CPUPercent != 5 {
+		t.Fatalf("last(2)=%v,%v", got[0].CPUPercent, got[1].CPUPercent)
+	}
+}
+
+func TestMinAvgMax(t *testing.T) {
+	lo, avg, hi := minAvgMax([]float64{2, 4, 9})
+	if lo != 2 || avg != 5 || hi != 9 {
+		t.Fatalf("got %v %v %v", lo, avg, hi)
+	}
+}
@@ -0,0 +1,448 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/cl4wb0rg/clawtop/internal/openclaw"
+)
+
+type action int
+
+const (
+	actQuit action = iota
+	actHelp
+	actRefresh
+	actFaster
+	actSlower
+	actNextProfile
+
+	actNextView
+	actPrevView
+	actViewOverview
+	actViewSessions
+	actViewSubagents
+	actViewTasks
+	actViewCrons
+	actViewTokens
+	actViewHost
+	actViewProcesses
+
+	actDown
+	actUp
+	actPageDown
+	actPageUp
+	actTop
+	actBottom
+	actFocusPrev
+	actFocusNext
+	actOpen
+	actBack
+
+	actSearch
+	actNextMatch
+	actPrevMatch
+	actSortPrev
+	actSortNext
+	actSortInvert
+
+	actToggle24h
+	actToggleHideRun
+	actTogglePrimary
+	actLevelError
+	actLevelWarn
+	actLevelInfo
+	actLevelDebug
+	actSourceCron
+	actSourceSubagent
+	actSourceTool
+)
+
+// binding ties an action to its keys (tea.Key.String() names) and help.
+type binding struct {
+	action action
+	name   string
+	group  string
+	keys   []string
+	help   string
+	// legend puts the binding on the one-line legend under the body
+	legend bool
+}
+
+// defaultBindings is the single source of truth for keys: Update
+// dispatches through it and the help overlay and legend are generated
+// from it. Groups appear in help in the order they first occur here.
+var defaultBindings = []binding{
+	{action: actQuit, name: "quit", group: "General", keys: []string{"q"}, help: "quit", legend: true},
+	{action: actHelp, name: "help", group: "General", keys: []string{"?"}, help: "toggle this help", legend: true},
+	{action: actRefresh, name: "refresh", group: "General", keys: []string{"r"}, help: "refresh now", legend: true},
+	{action: actFaster, name: "faster", group: "General", keys: []string{"+"}, help: "refresh faster (-0.5s)"},
+	{action: actSlower, name: "slower", group: "General", keys: []string{"-"}, help: "refresh slower (+0.5s)"},
+	{action: actNextProfile, name: "next_profile", group: "General", keys: []string{"p"}, help: "switch to the next profile"},
+
+	{action: actNextView, name: "next_view", group: "Views", keys: []string{"tab"}, help: "next view", legend: true},
+	{action: actPrevView, name: "prev_view", group: "Views", keys: []string{"shift+tab"}, help: "previous view"},
+	{action: actViewOverview, name: "view_overview", group: "Views", keys: []string{"f1"}, help: "Overview"},
+	{action: actViewSessions, name: "view_sessions", group: "Views", keys: []string{"f2"}, help: "Sessions"},
+	{action: actViewSubagents, name: "view_subagents", group: "Views", keys: []string{"f3"}, help: "Subagents"},
+	{action: actViewTasks, name: "view_tasks", group: "Views", keys: []string{"f4"}, help: "Tasks"},
+	{action: actViewCrons, name: "view_crons", group: "Views", keys: []string{"f5"}, help: "Crons"},
+	{action: actViewTokens, name: "view_tokens", group: "Views", keys: []string{"f6"}, help: "Tokens"},
+	{action: actViewHost, name: "view_host", group: "Views", keys: []string{"f7"}, help: "Host"},
+	{action: actViewProcesses, name: "view_processes", group: "Views", keys: []string{"f8"}, help: "Processes"},
+
+	{action: actDown, name: "down", group: "Tables", keys: []string{"j", "down"}, help: "cursor down"},
+	{action: actUp, name: "up", group: "Tables", keys: []string{"k", "up"}, help: "cursor up"},
+	{action: actPageDown, name: "page_down", group: "Tables", keys: []string{"pgdown"}, help: "page down"},
+	{action: actPageUp, name: "page_up", group: "Tables", keys: []string{"pgup"}, help: "page up"},
+	{action: actTop, name: "top", group: "Tables", keys: []string{"g", "home"}, help: "first row"},
+	{action: actBottom, name: "bottom", group: "Tables", keys: []string{"G", "end"}, help: "last row"},
+	{action: actFocusPrev, name: "focus_prev", group: "Tables", keys: []string{"h"}, help: "focus previous Overview panel"},
+	{action: actFocusNext, name: "focus_next", group: "Tables", keys: []string{"l"}, help: "focus next Overview panel"},
+	{action: actOpen, name: "open", group: "Tables", keys: []string{"enter"}, help: "open focused panel full-screen"},
+	{action: actBack, name: "back", group: "Tables", keys: []string{"esc"}, help: "clear search / back to Overview"},
+
+	{action: actSearch, name: "search", group: "Search & sort", keys: []string{"/"}, help: "filter focused panel", legend: true},
+	{action: actNextMatch, name: "next_match", group: "Search & sort", keys: []string{"n"}, help: "next match"},
+	{action: actPrevMatch, name: "prev_match", group: "Search & sort", keys: []string{"N"}, help: "previous match"},
+	{action: actSortPrev, name: "sort_prev", group: "Search & sort", keys: []string{"<"}, help: "sort sessions: previous column"},
+	{action: actSortNext, name: "sort_next", group: "Search & sort", keys: []string{">"}, help: "sort sessions: next column"},
+	{action: actSortInvert, name: "sort_invert", group: "Search & sort", keys: []string{"I"}, help: "invert session sort"},
+
+	{action: actToggle24h, name: "toggle_24h", group: "Session toggles", keys: []string{"1"}, help: "recent sessions only (last 24h by default)"},
+	{action: actToggleHideRun, name: "toggle_hide_run", group: "Session toggles", keys: []string{"2"}, help: "hide :run: sessions"},
+	{action: actTogglePrimary, name: "toggle_primary", group: "Session toggles", keys: []string{"3"}, help: "primary model only"},
+
+	{action: actLevelError, name: "level_error", group: "Task filters", keys: []string{"e"}, help: "error level"},
+	{action: actLevelWarn, name: "level_warn", group: "Task filters", keys: []string{"w"}, help: "warn level"},
+	{action: actLevelInfo, name: "level_info", group: "Task filters", keys: []string{"i"}, help: "info level"},
+	{action: actLevelDebug, name: "level_debug", group: "Task filters", keys: []string{"d"}, help: "debug level"},
+	{action: actSourceCron, name: "source_cron", group: "Task filters", keys: []string{"c"}, help: "cron source"},
+	{action: actSourceSubagent, name: "source_subagent", group: "Task filters", keys: []string{"s"}, help: "subagent source"},
+	{action: actSourceTool, name: "source_tool", group: "Task filters", keys: []string{"t"}, help: "tool source"},
+}
+
+type keymap struct {
+	bindings []binding
+	byKey    map[string]action
+}
+
+// quitKey always quits, whatever the keymap says, so a config can't leave
+// the alt screen without a way out.
+const quitKey = "ctrl+c"
+
+// buildKeymap applies user overrides (action name to keys) on top of
+// defaultBindings and rejects unknown actions, keys bound twice and
+// quitKey.
+func buildKeymap(overrides map[string][]string) (keymap, error) {
+	bs := make([]binding, len(defaultBindings))
+	copy(bs, defaultBindings)
+	byName := map[string]int{}
+	for i, b := range bs {
+		byName[b.name] = i
+	}
+	names := make([]string, 0, len(overrides))
+	for name := range overrides {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		i, ok := byName[name]
+		if !ok {
+			return keymap{}, fmt.Errorf("keys: unknown action %q", name)
+		}
+		bs[i].keys = append([]string(nil), overrides[name]...)
+	}
+
+	owner := map[string]string{}
+	var conflicts []string
+	for _, b := range bs {
+		for _, k := range b.keys {
+			if k == quitKey {
+				conflicts = append(conflicts, fmt.Sprintf("%q always quits and can't be bound to %s", k, b.name))
+				continue
+			}
+			if other, ok := owner[k]; ok {
+				conflicts = append(conflicts, fmt.Sprintf("%q is bound to both %s and %s", k, other, b.name))
+				continue
+			}
+			owner[k] = b.name
+		}
+	}
+	if len(conflicts) > 0 {
+		return keymap{}, fmt.Errorf("keys: %s", strings.Join(conflicts, "; "))
+	}
+	if len(bs[byName["quit"]].keys) == 0 {
+		return keymap{}, fmt.Errorf("keys: quit must have at least one key")
+	}
+	return newKeymap(bs), nil
+}
+
+func newKeymap(bs []binding) keymap {
+	km := keymap{bindings: bs, byKey: map[string]action{}}
+	for _, b := range bs {
+		for _, k := range b.keys {
+			km.byKey[k] = b.action
+		}
+	}
+	return km
+}
+
+func (km keymap) lookup(key string) (action, bool) {
+	a, ok := km.byKey[key]
+	return a, ok
+}
+
+// key is the first key bound to a, for hints like "24h[1]".
+func (km keymap) key(a action) string {
+	if keys := km.keys(a); len(keys) > 0 {
+		return keys[0]
+	}
+	return "?"
+}
+
+func (km keymap) keys(a action) []string {
+	for _, b := range km.bindings {
+		if b.action == a {
+			return b.keys
+		}
+	}
+	return nil
+}
+
+func (km keymap) legend() string {
+	parts := []string{}
+	for _, b := range km.bindings {
+		if b.legend && len(b.keys) > 0 {
+			parts = append(parts, b.keys[0]+" "+b.help)
+		}
+	}
+	return "Keys: " + strings.Join(parts, "  ")
+}
+
+// renderHelp lists every binding by group, in two columns when one
+// doesn't fit in h lines.
+func (km keymap) renderHelp(w, h int) string {
+	var groups []string
+	blocks := map[string][]string{}
+	for _, b := range km.bindings {
+		if _, ok := blocks[b.group]; !ok {
+			groups = append(groups, b.group)
+			blocks[b.group] = []string{titleStyle.Render(b.group)}
+		}
+		keys := strings.Join(b.keys, " / ")
+		if b.action == actQuit {
+			keys = strings.Join(append(append([]string(nil), b.keys...), quitKey), " / ")
+		}
+		if keys == "" {
+			keys = "(unbound)"
+		}
+		blocks[b.group] = append(blocks[b.group], padRight(keys, 12)+padRight(b.help, 32)+dimStyle.Render(b.name))
+	}
+	var rendered []string
+	total := 0
+	for _, g := range groups {
+		rendered = append(rendered, strings.Join(blocks[g], "\n"))
+		total += len(blocks[g]) + 1
+	}
+	footer := "Right-hand names rebind keys in the config file."
+	if search := km.keys(actSearch); len(search) > 0 {
+		footer += "  In the " + search[0] + " prompt: enter keep, esc clear, ctrl+u erase."
+	}
+	// any key closes help; these are the ones that do nothing else
+	if closeKeys := append(append([]string(nil), km.keys(actHelp)...), km.keys(actBack)...); len(closeKeys) > 0 {
+		footer += "  Press " + strings.Join(closeKeys, " or ") + " to close."
+	} else {
+		footer += "  Press any key to close."
+	}
+	footer = dimStyle.Render(footer)
+	if total <= h-2 || w < 80 {
+		return strings.Join(rendered, "\n\n") + "\n\n" + footer
+	}
+	// split where the left column reaches half the lines
+	left, right := []string{}, []string{}
+	n := 0
+	for _, r := range rendered {
+		if n < total/2 {
+			left = append(left, r)
+		} else {
+			right = append(right, r)
+		}
+		n += strings.Count(r, "\n") + 2
+	}
+	colW := (w - 2) / 2
+	cols := lipgloss.JoinHorizontal(lipgloss.Top,
+		lipgloss.NewStyle().Width(colW).Render(strings.Join(left, "\n\n")),
+		"  ",
+		lipgloss.NewStyle().Width(colW).Render(strings.Join(right, "\n\n")),
+	)
+	return cols + "\n\n" + footer
+}
+
+// do runs a if it applies in the current state.
+func (m model) do(a action) (tea.Model, tea.Cmd) {
+	switch a {
+	case actQuit:
+		return m, tea.Quit
+	case actHelp:
+		m.help = !m.help
+		return m, nil
+	case actRefresh:
+		return m, m.refreshNowCmd()
+	case actFaster:
+		if m.refresh > 500*time.Millisecond {
+			m.refresh -= 500 * time.Millisecond
+		}
+	case actSlower:
+		m.refresh += 500 * time.Millisecond
+	case actNextProfile:
+		return m.nextProfile()
+
+	case actNextView:
+		return m.setView((m.view + 1) % numViews)
+	case actPrevView:
+		return m.setView((m.view + numViews - 1) % numViews)
+	case actViewOverview, actViewSessions, actViewSubagents, actViewTasks, actViewCrons, actViewTokens, actViewHost, actViewProcesses:
+		return m.setView(view(a - actViewOverview))
+
+	case actDown:
+		return m.moveCursor(1), nil
+	case actUp:
+		return m.moveCursor(-1), nil
+	case actPageDown, actPageUp, actTop, actBottom:
+		p, ok := m.activePanel()
+		if !ok {
+			return m, nil
+		}
+		switch a {
+		case actPageDown:
+			return m.moveCursor(m.pageSize(p)), nil
+		case actPageUp:
+			return m.moveCursor(-m.pageSize(p)), nil
+		case actTop:
+			return m.moveCursor(-m.panelLen(p)), nil
+		}
+		return m.moveCursor(m.panelLen(p)), nil
+	case actFocusPrev:
+		if m.view == viewOverview {
+			m.focus = (m.focus + numPanels - 1) % numPanels
+		}
+	case actFocusNext:
+		if m.view == viewOverview {
+			m.focus = (m.focus + 1) % numPanels
+		}
+	case actOpen:
+		if m.view == viewOverview {
+			return m.setView(panelViews[m.focus])
+		}
+	case actBack:
+		if p, ok := m.activePanel(); ok && m.searches[p].query != "" {
+			m.searches[p] = search{}
+			return m, nil
+		}
+		return m.setView(viewOverview)
+
+	case actSearch:
+		if p, ok := m.activePanel(); ok {
+			m.prompt = true
+			m.promptPanel = p
+		}
+	case actNextMatch:
+		return m.jumpMatch(1), nil
+	case actPrevMatch:
+		return m.jumpMatch(-1), nil
+	case actSortPrev, actSortNext:
+		if p, ok := m.activePanel(); ok && p == panelSessions {
+			d := sessionSortKey(1)
+			if a == actSortPrev {
+				d = numSessionSorts - 1
+			}
+			m.sessionSort.key = (m.sessionSort.key + d) % numSessionSorts
+			// numbers and recency read best largest first, names A-Z
+			m.sessionSort.asc = m.sessionSort.key >= sortModel
+		}
+	case actSortInvert:
+		if p, ok := m.activePanel(); ok && p == panelSessions {
+			m.sessionSort.asc = !m.sessionSort.asc
+		}
+
+	case actToggle24h:
+		m.filter24h = !m.filter24h
+	case actToggleHideRun:
+		m.hideRunSessions = !m.hideRunSessions
+	case actTogglePrimary:
+		m.primaryModelOnly = !m.primaryModelOnly
+	case actLevelError:
+		m.levels[openclaw.LevelError] = !m.levels[openclaw.LevelError]
+	case actLevelWarn:
+		m.levels[openclaw.LevelWarn] = !m.levels[openclaw.LevelWarn]
+	case actLevelInfo:
+		m.levels[openclaw.LevelInfo] = !m.levels[openclaw.LevelInfo]
+	case actLevelDebug:
+		m.levels[openclaw.LevelDebug] = !m.levels[openclaw.LevelDebug]
+	case actSourceCron:
+		m.sources[openclaw.SourceCron] = !m.sources[openclaw.SourceCron]
+	case actSourceSubagent:
+		m.sources[openclaw.SourceSubagent] = !m.sources[openclaw.SourceSubagent]
+	case actSourceTool:
+		m.sources[openclaw.SourceTool] = !m.sources[openclaw.SourceTool]
+	}
+	return m, nil
+}
+
+// filtersLine shows the toggle states with their current keys.
+func (m model) filtersLine() string {
+	var b strings.Builder
+	for _, sp := range m.filterSpans() {
+		b.WriteString(sp.text)
+	}
+	return b.String()
+}
+
+// span is a piece of the filters line; clicking it (with --mouse) does act.
+type span struct {
+	text   string
+	act    action
+	active bool
+}
+
+func (m model) filterSpans() []span {
+	k := m.keys.key
+	txt := func(s string) span { return span{text: s} }
+	do := func(a action, s string) span { return span{text: s, act: a, active: true} }
+	levels := []action{actLevelError, actLevelWarn, actLevelInfo, actLevelDebug}
+	levelOn := []bool{m.levels[openclaw.LevelError], m.levels[openclaw.LevelWarn], m.levels[openclaw.LevelInfo], m.levels[openclaw.LevelDebug]}
+	sources := []action{actSourceCron, actSourceSubagent, actSourceTool}
+	sourceOn := []bool{m.sources[openclaw.SourceCron], m.sources[openclaw.SourceSubagent], m.sources[openclaw.SourceTool]}
+
+	out := []span{
+		txt("Filters: "),
+		do(actToggle24h, fmt.Sprintf("%s[%s]=%s", m.cfg.Filters.Window, k(actToggle24h), onOff(m.filter24h))),
+		txt("  "),
+		do(actToggleHideRun, fmt.Sprintf("hide:run[%s]=%s", k(actToggleHideRun), onOff(m.hideRunSessions))),
+		txt("  "),
+		do(actTogglePrimary, fmt.Sprintf("primary[%s]=%s (%s)", k(actTogglePrimary), onOff(m.primaryModelOnly), m.primaryModel)),
+	}
+	// "levels e/w/i/d=onononoff": each key and each on/off is its own target
+	group := func(name string, acts []action, on []bool) {
+		out = append(out, txt("  "+name+" "))
+		for i, a := range acts {
+			if i > 0 {
+				out = append(out, txt("/"))
+			}
+			out = append(out, do(a, k(a)))
+		}
+		out = append(out, txt("="))
+		for i, a := range acts {
+			out = append(out, do(a, onOff(on[i])))
+		}
+	}
+	group("levels", levels, levelOn)
+	group("src", sources, sourceOn)
+	return out
+}
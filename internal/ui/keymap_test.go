@@ -0,0 +1,81 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestDefaultBindings_CoverEveryAction(t *testing.T) {
+	seen := map[action]bool{}
+	keys := map[string]string{}
+	for _, b := range defaultBindings {
+		if seen[b.action] {
+			t.Fatalf("action %s bound twice", b.name)
+		}
+		seen[b.action] = true
+		for _, k := range b.keys {
+			if other, ok := keys[k]; ok {
+				t.Fatalf("key %q bound to both %s and %s", k, other, b.name)
+			}
+			keys[k] = b.name
+		}
+	}
+	for a := actQuit; a <= actSourceTool; a++ {
+		if !seen[a] {
+			t.Fatalf("action %d has no binding", a)
+		}
+	}
+}
+
+func TestBuildKeymap_Overrides(t *testing.T) {
+	km, err := buildKeymap(map[string][]string{
+		"level_debug":     {"D"},
+		"source_subagent": {"S"},
+		"down":            {"j", "down", "s"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a, ok := km.lookup("s"); !ok || a != actDown {
+		t.Fatalf("s -> %v %v", a, ok)
+	}
+	if _, ok := km.lookup("d"); ok {
+		t.Fatal("d should be unbound after rebinding level_debug")
+	}
+	if km.key(actLevelDebug) != "D" {
+		t.Fatalf("level_debug key=%q", km.key(actLevelDebug))
+	}
+}
+
+func TestBuildKeymap_Errors(t *testing.T) {
+	for name, o := range map[string]map[string][]string{
+		"conflict": {"down": {"s"}},
+		"unknown":  {"fly": {"x"}},
+		"no quit":  {"quit": {}},
+		"ctrl+c":   {"refresh": {"ctrl+c"}},
+	} {
+		if _, err := buildKeymap(o); err == nil {
+			t.Fatalf("%s: expected error", name)
+		}
+	}
+}
+
+func TestQuitKeyAlwaysQuits(t *testing.T) {
+	mm, err := New(Config{Keys: map[string][]string{"quit": {"x"}, "help": {"H"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := mm.(model)
+	for _, prompt := range []bool{false, true} {
+		m.prompt = prompt
+		if _, cmd := m.Update(tea.KeyMsg{Type: tea.KeyCtrlC}); cmd == nil || cmd() != tea.Quit() {
+			t.Fatalf("prompt=%v: ctrl+c didn't quit", prompt)
+		}
+	}
+	help := m.keys.renderHelp(200, 100)
+	if !strings.Contains(help, "x / ctrl+c") || !strings.Contains(help, "Press H or esc to close.") {
+		t.Fatalf("help:\n%s", help)
+	}
+}
@@ -0,0 +1,155 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// Layout breakpoints. Below narrowWidth the Overview stacks its panels in
+// one column; when the tables would not get minPanelLines each, the host
+// and token blocks shrink to one-line summaries.
+const (
+	narrowWidth   = 100
+	minPanelLines = 4 // title, header and two rows
+	detailMinBody = 14
+)
+
+// overviewLayout is where the Overview puts its panels, in lines. A panel
+// budget of 1 means it is collapsed to its title and row count.
+type overviewLayout struct {
+	twoCol        bool
+	leftW, rightW int
+	compact       bool
+	sep           int // blank lines between blocks
+	hostH         int
+	tokensH       int
+	panels        [numPanels]int
+}
+
+// panelWeights decide how spare lines are shared between the tables.
+var panelWeights = [numPanels]int{panelSessions: 3, panelSubagents: 1, panelTasks: 3, panelCrons: 2}
+
+// overviewLayout fits the Overview into w x h. hostH and tokensH are the
+// line counts of the full host and token blocks at the given width.
+func (m model) overviewLayout(w, h int) overviewLayout {
+	l := overviewLayout{twoCol: w >= narrowWidth, leftW: w, rightW: w, sep: 1}
+	if l.twoCol {
+		l.leftW = w/2 - 1
+		l.rightW = w - l.leftW - 1
+	}
+	want := [numPanels]int{}
+	caps := m.cfg.Limits.OverviewRows
+	for p, c := range [numPanels]int{caps.Sessions, caps.Subagents, caps.Tasks, caps.Crons} {
+		want[p] = m.panelLen(panel(p)) + 2
+		if c > 0 {
+			want[p] = min(want[p], c+2)
+		}
+	}
+	fit := func() bool {
+		l.hostH = strings.Count(m.renderHostBlock(l.leftW, l.compact), "\n") + 1
+		l.tokensH = strings.Count(m.renderTokensBlock(l.compact), "\n") + 1
+		top := l.hostH + l.tokensH + 2*l.sep
+		if l.twoCol {
+			left := allocLines(h-top-l.sep, want[:panelTasks], panelWeights[:panelTasks])
+			right := allocLines(h-l.sep, want[panelTasks:], panelWeights[panelTasks:])
+			copy(l.panels[:], append(left, right...))
+		} else {
+			copy(l.panels[:], allocLines(h-top-3*l.sep, want[:], panelWeights[:]))
+		}
+		for p, n := range l.panels {
+			if n < minPanelLines && n < want[p] {
+				return false
+			}
+		}
+		return true
+	}
+	if !fit() {
+		l.compact, l.sep = true, 0
+		fit()
+	}
+	return l
+}
+
+// rect is a screen area, in cells relative to the body's top left corner.
+type rect struct{ x, y, w, h int }
+
+func (r rect) contains(x, y int) bool {
+	return x >= r.x && x < r.x+r.w && y >= r.y && y < r.y+r.h
+}
+
+// rects is where each panel lands on screen; renderOverview draws every
+// panel in exactly its budget of lines, so this mirrors it.
+func (l overviewLayout) rects() [numPanels]rect {
+	var out [numPanels]rect
+	y := l.hostH + l.sep + l.tokensH + l.sep
+	for p := panel(0); p < numPanels; p++ {
+		if l.twoCol && p == panelTasks {
+			y = 0
+		}
+		x, w := 0, l.leftW
+		if l.twoCol && p >= panelTasks {
+			x, w = l.leftW+1, l.rightW
+		}
+		out[p] = rect{x: x, y: y, w: w, h: l.panels[p]}
+		y += l.panels[p] + l.sep
+	}
+	return out
+}
+
+// allocLines shares avail lines between panels that would like want lines
+// each, in proportion to weight. Every panel gets at least its summary
+// line; one that cannot show a row stays collapsed.
+func allocLines(avail int, want, weight []int) []int {
+	out := make([]int, len(want))
+	for i := range out {
+		out[i] = 1
+		avail--
+	}
+	stuck := make([]bool, len(want))
+	for avail > 0 {
+		best := -1
+		for i := range out {
+			if stuck[i] || out[i] >= want[i] {
+				continue
+			}
+			// fewest lines per unit of weight goes first
+			if best < 0 || out[i]*weight[best] < out[best]*weight[i] {
+				best = i
+			}
+		}
+		if best < 0 {
+			break
+		}
+		step := 1
+		if out[best] == 1 {
+			// expanding needs the header and at least one row
+			step = min(3, want[best]) - 1
+		}
+		if step > avail {
+			stuck[best] = true
+			continue
+		}
+		out[best] += step
+		avail -= step
+	}
+	return out
+}
+
+// clipLines cuts s to at most h lines of at most w cells, so nothing
+// wraps or scrolls the terminal.
+func clipLines(s string, w, h int) string {
+	lines := strings.Split(s, "\n")
+	if h > 0 && len(lines) > h {
+		lines = lines[:h]
+	}
+	if w > 0 {
+		for i, ln := range lines {
+			// Truncate always cuts one cell for the tail, even when ln fits
+			if ansi.StringWidth(ln) > w {
+				lines[i] = ansi.Truncate(ln, w, "…")
+			}
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,44 @@
+package ui
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/cl4wb0rg/clawtop/internal/openclaw"
+)
+
+func TestAllocLines(t *testing.T) {
+	got := allocLines(20, []int{50, 50}, []int{3, 1})
+	if got[0]+got[1] != 20 || got[0] <= got[1] {
+		t.Fatalf("alloc=%v", got)
+	}
+	// a panel that wants little gives the rest away
+	got = allocLines(20, []int{4, 50}, []int{3, 1})
+	if got[0] != 4 || got[1] != 16 {
+		t.Fatalf("alloc=%v", got)
+	}
+	// no room for a header and a row: stay collapsed
+	got = allocLines(4, []int{50, 50}, []int{1, 1})
+	if got[0]+got[1] > 4 || got[1] != 1 {
+		t.Fatalf("alloc=%v", got)
+	}
+}
+
+func TestOverviewFits(t *testing.T) {
+	var m model
+	now := time.Now()
+	for i := 0; i < 60; i++ {
+		m.sessions = append(m.sessions, openclaw.Session{Key: fmt.Sprintf("agent:main:s%d", i), UpdatedAt: now})
+		m.tasks = append(m.tasks, openclaw.Task{Title: "t", At: now})
+	}
+	for _, sz := range [][2]int{{80, 24}, {120, 40}, {60, 12}, {200, 60}} {
+		w, h := sz[0], sz[1]
+		out := m.renderOverview(w, h)
+		if lipgloss.Height(out) > h || lipgloss.Width(out) > w {
+			t.Fatalf("%dx%d: rendered %dx%d", w, h, lipgloss.Width(out), lipgloss.Height(out))
+		}
+	}
+}
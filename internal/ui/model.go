@@ -2,22 +2,50 @@ package ui
 
 import (
 	"fmt"
-	"os"
-	"path/filepath"
-	"sort"
 	"strings"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 
+	"github.com/cl4wb0rg/clawtop/internal/collect"
+	"github.com/cl4wb0rg/clawtop/internal/config"
 	"github.com/cl4wb0rg/clawtop/internal/host"
 	"github.com/cl4wb0rg/clawtop/internal/openclaw"
+	"github.com/cl4wb0rg/clawtop/internal/state"
 )
 
 type Config struct {
 	Paths openclaw.Paths
 	Refresh time.Duration
+	// SysRoot is the sysfs mount used for sensors (default /sys).
+	SysRoot string
+	// Filter is a query expression (see internal/query) applied to every
+	// panel at startup, as if typed at the / prompt.
+	Filter string
+	// Keys rebinds actions by name, replacing their default keys.
+	Keys map[string][]string
+	// Theme names the colour theme; empty means DefaultTheme.
+	Theme string
+	// Filters, Limits and Thresholds default to config.Default()'s when
+	// zero.
+	Filters    config.Filters
+	Limits     config.Limits
+	Thresholds config.Thresholds
+	// Profile names the active profile; Profiles are the ones p cycles
+	// through (none: no switching).
+	Profile  string
+	Profiles []Profile
+	// Instances, when set, are all read every refresh and shown together
+	// (Paths and Profiles are then unused).
+	Instances []collect.Instance
+	// OnRefresh, when set, is called from Update with what each refresh
+	// read, except refreshes started under a profile since switched away
+	// from.
+	OnRefresh func(h host.HostMetrics, ds []collect.Data)
+	// State is the UI saved by the last run (see StateOf); nil starts
+	// from the defaults. Refresh and Filter, when set, override it.
+	State *state.State
 }
 
 type model struct {
@@ -25,6 +53,20 @@ type model struct {
 
 	width int
 	height int
+	view view
+	// focus is the Overview panel that cursor keys drive
+	focus panel
+	tables [numPanels]table
+
+	// per-panel incremental search; prompt is open while typing into it
+	searches [numPanels]search
+	prompt bool
+	promptPanel panel
+
+	sessionSort sessionSort
+
+	keys keymap
+	help bool
 
 	refresh time.Duration
 	lastUpdate time.Time
@@ -32,7 +74,12 @@ type model struct {
 
 	// host stats
 	prevCPU *host.CPUStat
+	prevVM *host.VMStat
+	prevThrottles *uint64
 	host host.HostMetrics
+	hostHist hostHistory
+	// procs are only sampled while the Processes view is open
+	procs []host.Process
 
 	// openclaw data
 	sessions []openclaw.Session
@@ -40,6 +87,8 @@ type model struct {
 	crons []openclaw.CronJob
 	tasks []openclaw.Task
 	tokenSamples []openclaw.TokenSample
+	// instances holds each instance's own data when several are watched
+	instances []collect.Data
 
 	// filters/toggles
 	filter24h bool
@@ -49,6 +98,10 @@ type model struct {
 
 	levels map[openclaw.TaskLevel]bool
 	sources map[openclaw.TaskSource]bool
+
+	// profile indexes cfg.Profiles; refreshes started under another
+	// profile are dropped
+	profile int
 }
 
 type tickMsg time.Time
@@ -56,29 +109,51 @@ type tickMsg time.Time
 type refreshMsg struct {
 	err error
 	at time.Time
-	sessions []openclaw.Session
-	subagents []openclaw.SubagentRun
-	crons []openclaw.CronJob
-	tasks []openclaw.Task
-	tokenSamples []openclaw.TokenSample
-	host host.HostMetrics
-	cpu host.CPUStat
-	hasCPU bool
+	data collect.Data
+	instances []collect.Data
+	host collect.HostSample
+	procs []host.Process
+	profile int
 }
 
-func New(cfg Config) tea.Model {
-	m := model{cfg: cfg, refresh: cfg.Refresh}
-	if m.refresh <= 0 {
-		m.refresh = 2 * time.Second
+// New builds the UI model. It fails only on an invalid Config.Keys or
+// Config.Theme.
+func New(cfg Config) (tea.Model, error) {
+	keys, err := buildKeymap(cfg.Keys)
+	if err != nil {
+		return nil, err
 	}
-	// sensible defaults (clean, low-noise)
-	m.filter24h = true
-	m.hideRunSessions = true
-	m.primaryModelOnly = true
-	m.levels = map[openclaw.TaskLevel]bool{openclaw.LevelError: true, openclaw.LevelWarn: true, openclaw.LevelInfo: true, openclaw.LevelDebug: false}
-	// tool tasks can be very noisy; default off
-	m.sources = map[openclaw.TaskSource]bool{openclaw.SourceCron: true, openclaw.SourceSubagent: true, openclaw.SourceTool: false}
-	return m
+	if err := applyTheme(cfg.Theme); err != nil {
+		return nil, err
+	}
+	def := config.Default()
+	if cfg.Filters.Window == "" {
+		cfg.Filters = def.Filters
+	}
+	if cfg.Limits == (config.Limits{}) {
+		cfg.Limits = def.Limits
+	}
+	cfg.Thresholds = cfg.Thresholds.WithDefaults()
+	thresholds = cfg.Thresholds
+	m := model{cfg: cfg, refresh: 2 * time.Second, hostHist: newHostHistory(hostHistoryCap), keys: keys}
+	m.applyFilters(cfg.Filters)
+	for i, p := range cfg.Profiles {
+		if p.Name == cfg.Profile {
+			m.profile = i
+		}
+	}
+	if cfg.State != nil {
+		m.applyState(*cfg.State)
+	}
+	if cfg.Refresh > 0 {
+		m.refresh = cfg.Refresh
+	}
+	if cfg.Filter != "" {
+		for p := range m.searches {
+			m.searches[p] = newSearch(cfg.Filter)
+		}
+	}
+	return m, nil
 }
 
 func (m model) Init() tea.Cmd {
@@ -100,69 +175,68 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tickMsg:
 		return m, tea.Batch(m.refreshNowCmd(), tickCmd(m.refresh))
 	case refreshMsg:
+		if msg.profile != m.profile {
+			return m, nil
+		}
+		if m.cfg.OnRefresh != nil {
+			ds := msg.instances
+			if ds == nil {
+				ds = []collect.Data{msg.data}
+			}
+			m.cfg.OnRefresh(msg.host.Metrics, ds)
+		}
 		m.lastUpdate = msg.at
+		m.instances = msg.instances
 		if msg.err != nil {
 			m.err = msg.err
 			return m, nil
 		}
 		m.err = nil
-		m.sessions = msg.sessions
-		m.subagents = msg.subagents
-		m.crons = msg.crons
-		m.tasks = msg.tasks
-		m.tokenSamples = msg.tokenSamples
-		m.host = msg.host
-		if msg.hasCPU {
-			m.prevCPU = &msg.cpu
+		m.sessions = msg.data.Sessions
+		m.subagents = msg.data.Subagents
+		m.crons = msg.data.Crons
+		m.tasks = msg.data.Tasks
+		m.tokenSamples = msg.data.TokenSamples
+		m.host = msg.host.Metrics
+		if msg.procs != nil {
+			m.procs = msg.procs
+		}
+		// the first sample has no CPU delta yet; keep it out of the history
+		if m.prevCPU != nil {
+			m.hostHist.push(msg.host.Metrics)
+		}
+		if msg.host.CPU != nil {
+			m.prevCPU = msg.host.CPU
+		}
+		if msg.host.VM != nil {
+			m.prevVM = msg.host.VM
+		}
+		if msg.host.Throttles != nil {
+			m.prevThrottles = msg.host.Throttles
 		}
 		if m.primaryModel == "" {
-			m.primaryModel = guessPrimaryModel(m.sessions)
+			m.primaryModel = openclaw.PrimaryModel(m.sessions)
 		}
 		return m, nil
+	case tea.MouseMsg:
+		return m.updateMouse(msg)
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "q", "ctrl+c":
+		if msg.String() == quitKey {
 			return m, tea.Quit
-		case "r":
-			return m, m.refreshNowCmd()
-		case "+":
-			if m.refresh > 500*time.Millisecond {
-				m.refresh -= 500 * time.Millisecond
+		}
+		if m.prompt {
+			return m.updatePrompt(msg)
+		}
+		if m.help {
+			// any key closes help; ? and esc don't do anything else
+			m.help = false
+			if a, ok := m.keys.lookup(msg.String()); ok && a != actHelp && a != actBack {
+				return m.do(a)
 			}
 			return m, nil
-		case "-":
-			m.refresh += 500 * time.Millisecond
-			return m, nil
-		case "1":
-			m.filter24h = !m.filter24h
-			return m, nil
-		case "2":
-			m.hideRunSessions = !m.hideRunSessions
-			return m, nil
-		case "3":
-			m.primaryModelOnly = !m.primaryModelOnly
-			return m, nil
-		case "e":
-			m.levels[openclaw.LevelError] = !m.levels[openclaw.LevelError]
-			return m, nil
-		case "w":
-			m.levels[openclaw.LevelWarn] = !m.levels[openclaw.LevelWarn]
-			return m, nil
-		case "i":
-			m.levels[openclaw.LevelInfo] = !m.levels[openclaw.LevelInfo]
-			return m, nil
-		case "d":
-			m.levels[openclaw.LevelDebug] = !m.levels[openclaw.LevelDebug]
-			return m, nil
-		case "c":
-			m.sources[openclaw.SourceCron] = !m.sources[openclaw.SourceCron]
-			return m, nil
-		case "s":
-			m.sources[openclaw.SourceSubagent] = !m.sources[openclaw.SourceSubagent]
-			return m, nil
-		case "t":
-			m.sources[openclaw.SourceTool] = !m.sources[openclaw.SourceTool]
-			return m, nil
+		}
+		if a, ok := m.keys.lookup(msg.String()); ok {
+			return m.do(a)
 		}
 	}
 	return m, nil
@@ -170,148 +244,152 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 func (m model) View() string {
 	header := lipgloss.NewStyle().Bold(true).Render("clawtop")
+	if name, ok := m.profileName(); ok {
+		header += "  " + titleStyle.Render("["+name+"]")
+	}
 	sub := fmt.Sprintf("refresh=%s  updated=%s", m.refresh, relTime(m.lastUpdate))
 	if m.err != nil {
 		sub += "  err=" + m.err.Error()
 	}
 
-	filters := fmt.Sprintf(
-		"Filters: 24h[1]=%s  hide:run[2]=%s  primary[3]=%s (%s)  levels e/w/i/d=%s%s%s%s  src c/s/t=%s%s%s",
-		onOff(m.filter24h),
-		onOff(m.hideRunSessions),
-		onOff(m.primaryModelOnly), m.primaryModel,
-		onOff(m.levels[openclaw.LevelError]), onOff(m.levels[openclaw.LevelWarn]), onOff(m.levels[openclaw.LevelInfo]), onOff(m.levels[openclaw.LevelDebug]),
-		onOff(m.sources[openclaw.SourceCron]), onOff(m.sources[openclaw.SourceSubagent]), onOff(m.sources[openclaw.SourceTool]),
-	)
-	legend := dimStyle.Render("Keys: r refresh  +/- rate  q quit")
+	legend := dimStyle.Render(m.keys.legend())
+	if m.prompt {
+		legend = renderPrompt(m.searches[m.promptPanel])
+	}
 
-	leftW := m.width/2 - 1
-	if leftW < 40 {
-		leftW = 40
+	body := m.renderBody(m.width, m.bodyHeight())
+	if m.help {
+		body = m.keys.renderHelp(m.width, m.bodyHeight())
 	}
-	left := lipgloss.NewStyle().Width(leftW)
-	right := lipgloss.NewStyle().Width(m.width - leftW - 1)
 
-	leftBody := strings.Join([]string{
-		renderHost(m.host),
-		renderTokens(m.tokenSamples),
-		renderSessions(m.sessions, m.subagents, sessionFilters{only24h: m.filter24h, hideRun: m.hideRunSessions, primaryModelOnly: m.primaryModelOnly, primaryModel: m.primaryModel}),
-	}, "\n\n")
+	w, h := m.width, m.bodyHeight()
+	return strings.Join([]string{
+		clipLines(header+"  "+sub, w, 1),
+		clipLines(renderTabs(m.view, w), w, 1),
+		clipLines(m.filtersLine(), w, 1),
+		clipLines(body, w, h),
+		clipLines(legend, w, 1),
+	}, "\n") + "\n"
+}
 
-	rightBody := strings.Join([]string{
-		renderTasks(m.tasks, taskFilters{levels: m.levels, sources: m.sources}),
-		renderCrons(m.crons),
-	}, "\n\n")
+// moveCursor moves the active panel's cursor by delta rows.
+func (m model) moveCursor(delta int) model {
+	p, ok := m.activePanel()
+	if !ok {
+		return m
+	}
+	m.tables[p].move(delta, m.panelLen(p), m.pageSize(p))
+	return m
+}
 
-	body := lipgloss.JoinHorizontal(lipgloss.Top, left.Render(leftBody), right.Render(rightBody))
+// updatePrompt edits the search of the panel the prompt was opened on,
+// re-filtering on every keystroke.
+func (m model) updatePrompt(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	p := m.promptPanel
+	q := m.searches[p].query
+	switch msg.Type {
+	case tea.KeyEnter:
+		m.prompt = false
+		return m, nil
+	case tea.KeyEsc:
+		m.prompt = false
+		m.searches[p] = search{}
+		return m, nil
+	case tea.KeyBackspace:
+		if r := []rune(q); len(r) > 0 {
+			q = string(r[:len(r)-1])
+		}
+	case tea.KeyCtrlU:
+		q = ""
+	case tea.KeyRunes, tea.KeySpace:
+		q += string(msg.Runes)
+	default:
+		return m, nil
+	}
+	m.searches[p] = newSearch(q)
+	m.tables[p] = table{}
+	return m, nil
+}
 
-	return strings.Join([]string{header + "  " + sub, filters, body, legend}, "\n") + "\n"
+// jumpMatch moves to the next (dir=1) or previous (dir=-1) matching row,
+// wrapping around at either end.
+func (m model) jumpMatch(dir int) model {
+	p, ok := m.activePanel()
+	if !ok || !m.searches[p].active() {
+		return m
+	}
+	n := m.panelLen(p)
+	if n == 0 {
+		return m
+	}
+	t := m.tables[p]
+	t.cursor = (t.cursor + dir + n) % n
+	t.clamp(n, m.pageSize(p))
+	m.tables[p] = t
+	return m
+}
+
+// setView switches pages, sampling processes straight away so the
+// Processes view isn't empty for a whole refresh interval.
+func (m model) setView(v view) (tea.Model, tea.Cmd) {
+	m.view = v
+	if v == viewProcesses {
+		return m, m.refreshNowCmd()
+	}
+	return m, nil
 }
 
 func (m model) refreshNowCmd() tea.Cmd {
-	paths := m.cfg.Paths
-	prevCPU := m.prevCPU
+	prevHost := collect.HostSample{CPU: m.prevCPU, VM: m.prevVM, Throttles: m.prevThrottles}
+	sysRoot := m.cfg.SysRoot
+	return m.readCmd(func(at time.Time) collect.HostSample {
+		return collect.ReadHost(sysRoot, at, prevHost)
+	})
+}
+
+// readCmd reads everything a refresh shows, with the host metrics from
+// readHost.
+func (m model) readCmd(readHost func(at time.Time) collect.HostSample) tea.Cmd {
+	instances := m.watched()
+	limits := m.cfg.Limits
+	profile := m.profile
+	wantProcs := m.view == viewProcesses
+	prevProcs := m.procs
 	return func() tea.Msg {
 		at := time.Now()
 		var out refreshMsg
 		out.at = at
+		out.profile = profile
 
 		// host
-		cpu, err := host.ReadCPUStat()
-		if err == nil {
-			out.cpu = cpu
-			out.hasCPU = true
-		}
-		l1, l5, l15, _ := host.ReadLoadAvg()
-		total, avail, _ := host.ReadMemInfo()
-		used := total - avail
-		cpuPct := 0.0
-		if prevCPU != nil && out.hasCPU {
-			cpuPct = host.CPUPercent(*prevCPU, cpu)
+		out.host = readHost(at)
+		if wantProcs {
+			if procs, err := host.ReadProcesses(); err == nil {
+				host.ProcessCPUPercent(prevProcs, procs)
+				out.procs = procs
+			}
 		}
-		out.host = host.HostMetrics{At: at, CPUPercent: cpuPct, MemUsedBytes: used, MemTotalBytes: total, Load1: l1, Load5: l5, Load15: l15}
 
 		// openclaw
-		if sessions, err := openclaw.ReadSessionsJSON(paths.SessionsJSON); err == nil {
-			out.sessions = sessions
+		if len(instances) == 1 {
+			out.data = collect.Read(instances[0], limits)
 		} else {
-			out.err = err
-			return out
-		}
-		if sub, err := openclaw.ReadSubagentRuns(paths.SubagentRuns); err == nil {
-			out.subagents = sub
-		}
-		if cr, err := openclaw.ReadCronJobs(paths.CronJobs); err == nil {
-			out.crons = cr
+			out.instances = collect.ReadAll(instances, limits)
+			out.data = collect.Merge(out.instances, limits)
 		}
-
-		// tasks: cron finished + tool results + subagent runs
-		tasks := make([]openclaw.Task, 0, 64)
-		if st, err := os.Stat(paths.CronRunsDir); err == nil && st.IsDir() {
-			for _, cj := range out.crons {
-				p := openclaw.CronRunFile(paths.CronRunsDir, cj.ID)
-				if _, err := os.Stat(p); err == nil {
-					if t, ok, _ := openclaw.ReadLatestCronRun(p); ok {
-						t.Title = "cron: " + cj.Name
-						tasks = append(tasks, t)
-					}
-				}
-			}
-		}
-		// tool tasks from main session file if present in sessions.json
-		mainSess := findSession(out.sessions, "agent:main:main")
-		if mainSess != nil {
-			// session file is in sessions.json as sessionFile but we don't parse it. fallback to default.
-			// for MVP we assume default location.
-			sessionFile := paths.OpenClawRoot + "/agents/main/sessions/" + "" // unused
-			_ = sessionFile
-		}
-		// heuristic: pick newest agent:main:main session file by glob
-		glob := paths.OpenClawRoot + "/agents/main/sessions/*.jsonl"
-		matches, _ := filepathGlob(glob)
-		sort.Slice(matches, func(i, j int) bool {
-			iSt, _ := os.Stat(matches[i])
-			jSt, _ := os.Stat(matches[j])
-			if iSt == nil || jSt == nil {
-				return matches[i] > matches[j]
-			}
-			return iSt.ModTime().After(jSt.ModTime())
-		})
-		if len(matches) > 0 {
-			if toolTasks, err := openclaw.ReadToolTasks(matches[0], 400, 25); err == nil {
-				tasks = append(tasks, toolTasks...)
-			}
-		}
-		for _, sa := range out.subagents {
-			lvl := openclaw.LevelInfo
-			detail := firstN(sa.Task, 90)
-			tasks = append(tasks, openclaw.Task{At: sa.CreatedAt, Level: lvl, Source: openclaw.SourceSubagent, Title: "subagent: " + sa.Label, Detail: detail})
-		}
-		sort.Slice(tasks, func(i, j int) bool { return tasks[i].At.After(tasks[j].At) })
-		if len(tasks) > 40 {
-			tasks = tasks[:40]
-		}
-		out.tasks = tasks
-
-		// tokens optional
-		if samples, err := openclaw.ReadTokenSamples(paths.TokensJSONL, 48); err == nil {
-			out.tokenSamples = samples
-		}
-
+		out.err = out.data.Err
 		return out
 	}
 }
 
-// filepathGlob wrapped for testability.
-var filepathGlob = func(pattern string) ([]string, error) { return filepath.Glob(pattern) }
-
-func findSession(s []openclaw.Session, key string) *openclaw.Session {
-	for i := range s {
-		if s[i].Key == key {
-			return &s[i]
-		}
+// watched is what a refresh reads: every configured instance, or the
+// active profile's paths under the profile's name, so exporters keep
+// each profile's counters apart.
+func (m model) watched() []collect.Instance {
+	if len(m.cfg.Instances) > 0 {
+		return m.cfg.Instances
 	}
-	return nil
+	return []collect.Instance{{Name: m.cfg.Profile, Paths: m.cfg.Paths, Err: m.profileErr()}}
 }
 
 func relTime(t time.Time) string {
@@ -338,33 +416,6 @@ func onOff(b bool) string {
 	return "off"
 }
 
-func guessPrimaryModel(s []openclaw.Session) string {
-	for _, sess := range s {
-		if sess.Key == "agent:main:main" && sess.Model != "" {
-			return sess.Model
-		}
-	}
-	// fallback most common
-	m := map[string]int{}
-	for _, sess := range s {
-		if sess.Model != "" {
-			m[sess.Model]++
-		}
-	}
-	best := ""
-	bestN := 0
-	for k, n := range m {
-		if n > bestN {
-			best, bestN = k, n
-		}
-	}
-	return best
-}
-
 func firstN(s string, n int) string {
-	s = strings.TrimSpace(strings.ReplaceAll(s, "\n", " "))
-	if len(s) <= n {
-		return s
-	}
-	return s[:n-1] + "…"
+	return truncate(strings.TrimSpace(strings.ReplaceAll(s, "\n", " ")), n)
 }
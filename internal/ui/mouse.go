@@ -0,0 +1,113 @@
+package ui
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Screen rows above the body: header, tabs and filters.
+const (
+	tabsRow    = 1
+	filtersRow = 2
+	bodyTop    = 3
+)
+
+// wheelStep is how many rows one wheel notch scrolls.
+const wheelStep = 3
+
+// updateMouse handles mouse events, which only arrive when clawtop runs
+// with --mouse: clicks on tabs, filter toggles, panels and rows, and the
+// wheel over a table.
+func (m model) updateMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	if m.prompt {
+		return m, nil
+	}
+	if msg.Button == tea.MouseButtonWheelUp || msg.Button == tea.MouseButtonWheelDown {
+		delta := wheelStep
+		if msg.Button == tea.MouseButtonWheelUp {
+			delta = -wheelStep
+		}
+		if p, ok := m.panelAt(msg.X, msg.Y-bodyTop); ok {
+			m.tables[p].move(delta, m.panelLen(p), m.pageSize(p))
+		}
+		return m, nil
+	}
+	if msg.Action != tea.MouseActionPress || msg.Button != tea.MouseButtonLeft {
+		return m, nil
+	}
+	if m.help {
+		m.help = false
+		return m, nil
+	}
+	switch msg.Y {
+	case tabsRow:
+		x := 0
+		for v, tab := range tabParts(m.view, m.width) {
+			x += lipgloss.Width(tab)
+			if msg.X < x {
+				return m.setView(view(v))
+			}
+		}
+		return m, nil
+	case filtersRow:
+		x := 0
+		for _, sp := range m.filterSpans() {
+			x += lipgloss.Width(sp.text)
+			if msg.X < x {
+				if sp.active {
+					return m.do(sp.act)
+				}
+				break
+			}
+		}
+		return m, nil
+	}
+	y := msg.Y - bodyTop
+	p, ok := m.panelAt(msg.X, y)
+	if !ok {
+		return m, nil
+	}
+	if m.view == viewOverview {
+		m.focus = p
+	}
+	if row, ok := m.rowAt(p, y); ok {
+		m.tables[p].cursor = row
+		m.tables[p].clamp(m.panelLen(p), m.pageSize(p))
+	}
+	return m, nil
+}
+
+// panelRect is where p is drawn in the body, if it is on screen. In the
+// full-screen views the panel owns the whole body, detail pane included.
+func (m model) panelRect(p panel) (rect, bool) {
+	if m.view == viewOverview {
+		return m.overviewLayout(m.width, m.bodyHeight()).rects()[p], true
+	}
+	if ap, ok := m.activePanel(); ok && ap == p {
+		return rect{w: m.width, h: m.bodyHeight()}, true
+	}
+	return rect{}, false
+}
+
+// panelAt finds the panel under body coordinates x, y.
+func (m model) panelAt(x, y int) (panel, bool) {
+	for p := panel(0); p < numPanels; p++ {
+		if r, ok := m.panelRect(p); ok && r.contains(x, y) {
+			return p, true
+		}
+	}
+	return 0, false
+}
+
+// rowAt maps body line y to a row index of p. The first two lines of a
+// panel are its title and column header.
+func (m model) rowAt(p panel, y int) (int, bool) {
+	r, _ := m.panelRect(p)
+	i := y - r.y - 2
+	t, n, page := m.tables[p], m.panelLen(p), m.pageSize(p)
+	t.clamp(n, page)
+	if i < 0 || i >= page || t.offset+i >= n {
+		return 0, false
+	}
+	return t.offset + i, true
+}
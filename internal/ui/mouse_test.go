@@ -0,0 +1,81 @@
+package ui
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/cl4wb0rg/clawtop/internal/openclaw"
+)
+
+func mouseModel(t *testing.T) model {
+	t.Helper()
+	mm, err := New(Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := mm.(model)
+	m.width, m.height = 120, 40
+	now := time.Now()
+	for i := 0; i < 30; i++ {
+		m.sessions = append(m.sessions, openclaw.Session{Key: fmt.Sprintf("agent:main:s%d", i), UpdatedAt: now})
+		m.tasks = append(m.tasks, openclaw.Task{Title: "t", Level: openclaw.LevelInfo, Source: openclaw.SourceCron, At: now.Add(-time.Duration(i) * time.Minute)})
+	}
+	return m
+}
+
+func click(m model, x, y int) model {
+	mm, _ := m.Update(tea.MouseMsg{X: x, Y: y, Action: tea.MouseActionPress, Button: tea.MouseButtonLeft})
+	return mm.(model)
+}
+
+func TestMouseSelectRow(t *testing.T) {
+	m := mouseModel(t)
+	r := m.overviewLayout(m.width, m.bodyHeight()).rects()[panelTasks]
+	// title, header, then rows 0, 1, 2, 3
+	m = click(m, r.x+1, bodyTop+r.y+5)
+	if m.focus != panelTasks || m.tables[panelTasks].cursor != 3 {
+		t.Fatalf("focus=%d cursor=%d", m.focus, m.tables[panelTasks].cursor)
+	}
+	// the title focuses without moving the cursor
+	r = m.overviewLayout(m.width, m.bodyHeight()).rects()[panelSessions]
+	m = click(m, r.x, bodyTop+r.y)
+	if m.focus != panelSessions || m.tables[panelSessions].cursor != 0 {
+		t.Fatalf("focus=%d cursor=%d", m.focus, m.tables[panelSessions].cursor)
+	}
+}
+
+func TestMouseWheel(t *testing.T) {
+	m := mouseModel(t)
+	r := m.overviewLayout(m.width, m.bodyHeight()).rects()[panelSessions]
+	mm, _ := m.Update(tea.MouseMsg{X: r.x, Y: bodyTop + r.y + 2, Action: tea.MouseActionPress, Button: tea.MouseButtonWheelDown})
+	m = mm.(model)
+	if got := m.tables[panelSessions].cursor; got != wheelStep {
+		t.Fatalf("cursor=%d", got)
+	}
+}
+
+func TestMouseFiltersAndTabs(t *testing.T) {
+	m := mouseModel(t)
+	on := m.filter24h
+	m = click(m, len("Filters: "), filtersRow)
+	if m.filter24h == on {
+		t.Fatal("24h toggle not clicked")
+	}
+	// "Filters: " itself is not a toggle
+	m = click(m, 0, filtersRow)
+	if m.filter24h == on {
+		t.Fatal("label toggled a filter")
+	}
+	x := 0
+	for _, tab := range tabParts(m.view, m.width)[:int(viewTasks)] {
+		x += lipgloss.Width(tab)
+	}
+	m = click(m, x+1, tabsRow)
+	if m.view != viewTasks {
+		t.Fatalf("view=%s", m.view)
+	}
+}
@@ -0,0 +1,85 @@
+package ui
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/cl4wb0rg/clawtop/internal/config"
+	"github.com/cl4wb0rg/clawtop/internal/openclaw"
+)
+
+// Profile is one OpenClaw installation the UI can switch to.
+type Profile struct {
+	// Name is the config profile name; empty is the top-level config.
+	Name    string
+	Paths   openclaw.Paths
+	Filter  string
+	Filters config.Filters
+	// Err is why Paths could not be resolved, shown instead of data.
+	Err error
+}
+
+// profileName is the header label of the active profile, if there are
+// profiles at all.
+func (m model) profileName() (string, bool) {
+	if len(m.cfg.Instances) > 0 {
+		names := make([]string, len(m.cfg.Instances))
+		for i, in := range m.cfg.Instances {
+			names[i] = in.Name
+		}
+		return strings.Join(names, ","), true
+	}
+	if len(m.cfg.Profiles) == 0 {
+		if m.cfg.Profile != "" {
+			return m.cfg.Profile, true
+		}
+		return "", false
+	}
+	if n := m.cfg.Profiles[m.profile].Name; n != "" {
+		return n, true
+	}
+	return "default", true
+}
+
+func (m model) profileErr() error {
+	if len(m.cfg.Profiles) == 0 {
+		return nil
+	}
+	return m.cfg.Profiles[m.profile].Err
+}
+
+// applyFilters resets the filter toggles to f.
+func (m *model) applyFilters(f config.Filters) {
+	m.filter24h = f.Recent
+	m.hideRunSessions = f.HideRunSessions
+	m.primaryModelOnly = f.PrimaryModelOnly
+	m.levels = map[openclaw.TaskLevel]bool{}
+	for _, l := range []openclaw.TaskLevel{openclaw.LevelError, openclaw.LevelWarn, openclaw.LevelInfo, openclaw.LevelDebug} {
+		m.levels[l] = f.Levels[string(l)]
+	}
+	m.sources = map[openclaw.TaskSource]bool{}
+	for _, src := range []openclaw.TaskSource{openclaw.SourceCron, openclaw.SourceSubagent, openclaw.SourceTool} {
+		m.sources[src] = f.Sources[string(src)]
+	}
+}
+
+// nextProfile switches to the next profile: its paths, filters and
+// searches replace the current ones and its data is read right away.
+func (m model) nextProfile() (tea.Model, tea.Cmd) {
+	if len(m.cfg.Profiles) < 2 {
+		return m, nil
+	}
+	m.profile = (m.profile + 1) % len(m.cfg.Profiles)
+	p := m.cfg.Profiles[m.profile]
+	m.cfg.Paths, m.cfg.Filters, m.cfg.Profile = p.Paths, p.Filters, p.Name
+	m.applyFilters(p.Filters)
+	for i := range m.searches {
+		m.searches[i] = newSearch(p.Filter)
+	}
+	m.tables = [numPanels]table{}
+	m.sessions, m.subagents, m.crons, m.tasks, m.tokenSamples = nil, nil, nil, nil, nil
+	m.primaryModel = ""
+	m.err = nil
+	return m, m.refreshNowCmd()
+}
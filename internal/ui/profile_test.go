@@ -0,0 +1,104 @@
+package ui
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/cl4wb0rg/clawtop/internal/collect"
+	"github.com/cl4wb0rg/clawtop/internal/config"
+	"github.com/cl4wb0rg/clawtop/internal/host"
+	"github.com/cl4wb0rg/clawtop/internal/openclaw"
+)
+
+func TestNextProfile(t *testing.T) {
+	staging := config.Default().Filters
+	staging.Levels = map[string]bool{"debug": true}
+	mm, err := New(Config{
+		Profile: "prod",
+		Profiles: []Profile{
+			{Name: "prod", Paths: openclaw.Paths{OpenClawRoot: "/srv/prod"}},
+			{Name: "staging", Paths: openclaw.Paths{OpenClawRoot: "/srv/staging"}, Filter: "cron", Filters: staging},
+			{Name: "gone", Err: errors.New("openclaw root not found")},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := mm.(model)
+	m.sessions = []openclaw.Session{{Key: "agent:main:main"}}
+	m.primaryModel = "gpt-5.2"
+
+	mm, cmd := m.do(actNextProfile)
+	m = mm.(model)
+	if name, _ := m.profileName(); name != "staging" || m.cfg.Paths.OpenClawRoot != "/srv/staging" || cmd == nil {
+		t.Fatalf("profile=%s root=%s", name, m.cfg.Paths.OpenClawRoot)
+	}
+	if m.sessions != nil || m.primaryModel != "" || !m.levels[openclaw.LevelDebug] || m.levels[openclaw.LevelError] || m.searches[panelTasks].query != "cron" {
+		t.Fatalf("not reset: sessions=%v levels=%v", m.sessions, m.levels)
+	}
+	// a refresh started under the old profile is dropped
+	mm, _ = m.Update(refreshMsg{data: collect.Data{Sessions: []openclaw.Session{{Key: "old"}}}})
+	if mm.(model).sessions != nil {
+		t.Fatal("stale refresh applied")
+	}
+
+	mm, _ = m.do(actNextProfile)
+	m = mm.(model)
+	if msg := m.refreshNowCmd()().(refreshMsg); msg.err == nil || msg.profile != 2 {
+		t.Fatalf("err=%v", msg.err)
+	}
+}
+
+func TestInstanceColumn(t *testing.T) {
+	mm, err := New(Config{Instances: []collect.Instance{{Name: "prod"}, {Name: "staging"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := mm.(model)
+	m.sessions = []openclaw.Session{{Instance: "prod", Key: "a"}, {Instance: "staging", Key: "b"}}
+	m.primaryModelOnly, m.filter24h = false, false
+	cols, rows, _ := m.panelRows(panelSessions, false)
+	if cols[0].title != "INST" || rows[1][0].text != "staging" {
+		t.Fatalf("cols=%v rows=%v", cols, rows)
+	}
+	if f := m.panelDetail(panelSessions, 0); f[0] != [2]string{"Instance", "prod"} {
+		t.Fatalf("detail=%v", f)
+	}
+	if _, rows, _ := m.panelRows(panelSubagents, false); len(rows) != 0 {
+		t.Fatalf("subagents: %v", rows)
+	}
+	if name, _ := m.profileName(); name != "prod,staging" {
+		t.Fatalf("header name %q", name)
+	}
+}
+
+func TestOnRefreshPerProfile(t *testing.T) {
+	var pushed []string
+	mm, err := New(Config{
+		Profile: "prod",
+		Profiles: []Profile{
+			{Name: "prod", Paths: openclaw.Paths{OpenClawRoot: t.TempDir()}},
+			{Name: "staging", Paths: openclaw.Paths{OpenClawRoot: t.TempDir()}},
+		},
+		OnRefresh: func(_ host.HostMetrics, ds []collect.Data) {
+			for _, d := range ds {
+				pushed = append(pushed, d.Instance)
+			}
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := mm.(model)
+	stale := m.refreshNowCmd()
+	mm, _ = m.Update(m.refreshNowCmd()())
+	mm, _ = mm.(model).do(actNextProfile)
+	m = mm.(model)
+	// prod's late refresh is neither shown nor pushed
+	m.Update(stale())
+	mm.(model).Update(m.refreshNowCmd()())
+	if strings.Join(pushed, ",") != "prod,staging" {
+		t.Fatalf("pushed %v", pushed)
+	}
+}
@@ -2,42 +2,113 @@ package ui
 
 import (
 	"fmt"
-	"sort"
 	"strings"
 	"time"
 
 	"github.com/charmbracelet/lipgloss"
 
+	"github.com/cl4wb0rg/clawtop/internal/collect"
+	"github.com/cl4wb0rg/clawtop/internal/config"
 	"github.com/cl4wb0rg/clawtop/internal/host"
 	"github.com/cl4wb0rg/clawtop/internal/openclaw"
 )
 
-type sessionFilters struct {
-	only24h bool
-	hideRun bool
-	primaryModelOnly bool
-	primaryModel string
-}
-
-type taskFilters struct {
-	levels map[openclaw.TaskLevel]bool
-	sources map[openclaw.TaskSource]bool
-}
-
 var (
 	titleStyle = lipgloss.NewStyle().Bold(true)
 	dimStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
 	badStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("1"))
 	okStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("2"))
+	warnStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("3"))
 )
 
+// thresholds grade the host panel's values; New sets them from its Config.
+var thresholds = config.Default().Thresholds
+
 func renderHost(m host.HostMetrics) string {
-	return titleStyle.Render("Host") + "\n" +
-		fmt.Sprintf("CPU: %5.1f%%   Mem: %s/%s   Load: %.2f %.2f %.2f",
+	swap := "-"
+	if m.SwapTotalBytes > 0 {
+		pct := float64(m.SwapUsedBytes) / float64(m.SwapTotalBytes) * 100
+		swap = thresh(pct, thresholds.Swap).render(host.HumanBytes(m.SwapUsedBytes) + "/" + host.HumanBytes(m.SwapTotalBytes))
+	}
+	lines := []string{
+		titleStyle.Render("Host"),
+		fmt.Sprintf("CPU: %5.1f%%   Mem: %s/%s   Swap: %s   Load: %.2f %.2f %.2f",
 			m.CPUPercent,
 			host.HumanBytes(m.MemUsedBytes), host.HumanBytes(m.MemTotalBytes),
+			swap,
 			m.Load1, m.Load5, m.Load15,
-		)
+		),
+	}
+	psi := dimStyle.Render("PSI: (unavailable)")
+	if m.HasPSI {
+		psi = fmt.Sprintf("PSI some/full: cpu %s  mem %s  io %s",
+			renderPressure(m.PSICPU), renderPressure(m.PSIMemory), renderPressure(m.PSIIO))
+	}
+	majflt := thresh(m.MajFaultsPerSec, thresholds.MajorFaults).render(fmt.Sprintf("%.0f/s", m.MajFaultsPerSec))
+	lines = append(lines, psi+"   majflt: "+majflt)
+	if sensors := renderSensors(m.Sensors); sensors != "" {
+		lines = append(lines, sensors)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// renderHostLine is the one-line host summary used when the terminal is
+// too short for the full block.
+func renderHostLine(m host.HostMetrics) string {
+	ln := titleStyle.Render("Host") + fmt.Sprintf("  CPU %.0f%%  Mem %s/%s  Load %.2f",
+		m.CPUPercent, host.HumanBytes(m.MemUsedBytes), host.HumanBytes(m.MemTotalBytes), m.Load1)
+	if m.HasPSI {
+		ln += "  PSI mem " + renderPressure(m.PSIMemory)
+	}
+	if t, ok := m.Sensors.MaxTemp(); ok {
+		ln += "  " + thresh(t.Celsius, tempThreshold(t)).render(fmt.Sprintf("%.0f°C", t.Celsius))
+	}
+	return ln
+}
+
+func renderSensors(s host.Sensors) string {
+	parts := []string{}
+	if t, ok := s.MaxTemp(); ok {
+		parts = append(parts, "Temp: "+thresh(t.Celsius, tempThreshold(t)).render(fmt.Sprintf("%.0f°C", t.Celsius))+
+			dimStyle.Render(" ("+t.Chip+" "+t.Label+")"))
+	}
+	parts = append(parts, renderFreq(s)...)
+	return strings.Join(parts, "   ")
+}
+
+func renderFreq(s host.Sensors) []string {
+	parts := []string{}
+	if s.CPUFreqMHz > 0 {
+		freq := fmt.Sprintf("Freq: %.2f", s.CPUFreqMHz/1000)
+		if s.CPUMaxFreqMHz > 0 {
+			freq += fmt.Sprintf("/%.2f", s.CPUMaxFreqMHz/1000)
+		}
+		parts = append(parts, freq+" GHz")
+	}
+	// the count is since boot; only throttling since the last refresh warns
+	switch {
+	case s.NewThrottles > 0:
+		parts = append(parts, sevWarn.render(fmt.Sprintf("throttled: %d (+%d)", s.ThrottleCount, s.NewThrottles)))
+	case s.ThrottleCount > 0:
+		parts = append(parts, dimStyle.Render(fmt.Sprintf("throttled: %d", s.ThrottleCount)))
+	}
+	return parts
+}
+
+// tempThreshold lowers the bad threshold for parts with a low trip point.
+func tempThreshold(t host.Temp) config.Threshold {
+	th := thresholds.Temp
+	if t.CritC > 0 && t.CritC-5 < th.Bad {
+		th.Bad = t.CritC - 5
+		th.Warn = min(th.Warn, th.Bad)
+	}
+	return th
+}
+
+// renderPressure shows the 10s some/full averages, which react fastest.
+func renderPressure(p host.Pressure) string {
+	return thresh(p.SomeAvg10, thresholds.PSISome).render(fmt.Sprintf("%.1f", p.SomeAvg10)) + "/" +
+		thresh(p.FullAvg10, thresholds.PSIFull).render(fmt.Sprintf("%.1f", p.FullAvg10))
 }
 
 func renderTokens(samples []openclaw.TokenSample) string {
@@ -52,27 +123,105 @@ func renderTokens(samples []openclaw.TokenSample) string {
 		)
 }
 
-func sparkline(samples []openclaw.TokenSample) string {
-	vals := make([]int64, 0, len(samples))
-	for _, s := range samples {
-		vals = append(vals, s.OpenClawTotal)
+// renderTokensLine is renderTokens squeezed onto one line.
+func renderTokensLine(samples []openclaw.TokenSample) string {
+	if len(samples) == 0 {
+		return titleStyle.Render("Tokens") + "  " + dimStyle.Render("(no tokens.jsonl)")
 	}
-	min, max := vals[0], vals[0]
-	for _, v := range vals {
-		if v < min {
-			min = v
+	last := samples[len(samples)-1]
+	return titleStyle.Render("Tokens") + fmt.Sprintf("  %d  $%.2f  ", last.OpenClawTotal, last.ClaudeCostUSD) +
+		sparkline(samples[max(0, len(samples)-20):])
+}
+
+// renderInstances adds a health line per watched instance under the
+// combined token totals: sessions, running subagents, failing crons and
+// the instance's own tokens, or why it could not be read.
+func renderInstances(ds []collect.Data) string {
+	if len(ds) == 0 {
+		return ""
+	}
+	w := 4
+	for _, d := range ds {
+		w = max(w, textWidth(d.Instance))
+	}
+	w = min(w, 16)
+	lines := make([]string, 0, len(ds))
+	for _, d := range ds {
+		sev := instanceHealth(d)
+		name := padRight(truncate(d.Instance, w), w)
+		if d.Err != nil {
+			lines = append(lines, sev.render(name)+"  "+sev.style().Render(d.Err.Error()))
+			continue
 		}
-		if v > max {
-			max = v
+		running, failing := 0, 0
+		for _, r := range d.Subagents {
+			if r.Status() == "running" {
+				running++
+			}
+		}
+		for _, c := range d.Crons {
+			if c.LastStatus == "error" {
+				failing++
+			}
+		}
+		tok := "-"
+		if n := len(d.TokenSamples); n > 0 {
+			last := d.TokenSamples[n-1]
+			tok = fmt.Sprintf("%d  $%.2f", last.OpenClawTotal, last.ClaudeCostUSD)
+		}
+		updated := time.Time{}
+		if len(d.Sessions) > 0 {
+			updated = d.Sessions[0].UpdatedAt
+		}
+		lines = append(lines, sev.render(name)+fmt.Sprintf("  %d sess  %d run  %d cron err  %s  ", len(d.Sessions), running, failing, tok)+
+			dimStyle.Render(relTime(updated)))
+	}
+	return "\n" + strings.Join(lines, "\n")
+}
+
+// renderInstancesLine is renderInstances as marks after the one-line
+// tokens summary.
+func renderInstancesLine(ds []collect.Data) string {
+	var b strings.Builder
+	for _, d := range ds {
+		b.WriteString("  " + instanceHealth(d).render(d.Instance))
+	}
+	return b.String()
+}
+
+// instanceHealth is bad when the instance can't be read and a warning when
+// one of its crons last failed.
+func instanceHealth(d collect.Data) severity {
+	if d.Err != nil {
+		return sevBad
+	}
+	for _, c := range d.Crons {
+		if c.LastStatus == "error" {
+			return sevWarn
 		}
 	}
+	return sevOK
+}
+
+func sparkline(samples []openclaw.TokenSample) string {
+	vals := make([]float64, 0, len(samples))
+	for _, s := range samples {
+		vals = append(vals, float64(s.OpenClawTotal))
+	}
+	lo, _, hi := minAvgMax(vals)
+	return sparkRange(vals, lo, hi)
+}
+
+// sparkRange draws vals scaled between lo and hi. Use fixed bounds (e.g.
+// 0..100 for CPU) when relative scaling would exaggerate small changes.
+func sparkRange(vals []float64, lo, hi float64) string {
 	blocks := []rune("▁▂▃▄▅▆▇█")
-	if max == min {
+	if hi <= lo {
 		return strings.Repeat(string(blocks[len(blocks)/2]), len(vals))
 	}
 	b := strings.Builder{}
 	for _, v := range vals {
-		r := float64(v-min) / float64(max-min)
+		r := (v - lo) / (hi - lo)
 		idx := int(r * float64(len(blocks)-1))
 		if idx < 0 {
 			idx = 0
@@ -85,95 +234,188 @@ func sparkline(samples []openclaw.TokenSample) string {
 	return b.String()
 }
 
-func renderSessions(sessions []openclaw.Session, subs []openclaw.SubagentRun, f sessionFilters) string {
-	lines := []string{titleStyle.Render("Sessions / Subagents")}
-	cut := time.Now().Add(-24 * time.Hour)
-	for _, s := range sessions {
-		if f.only24h && s.UpdatedAt.Before(cut) {
-			continue
-		}
-		if f.hideRun && strings.Contains(s.Key, ":run:") {
-			continue
-		}
-		if f.primaryModelOnly && f.primaryModel != "" && s.Model != f.primaryModel {
-			continue
+// renderHostHistory draws CPU, memory and load sparklines over as many
+// samples as fit in width, with min/avg/max for that visible window.
+func renderHostHistory(h hostHistory, width int) string {
+	const statsW = 34 // "  min 100.0 avg 100.0 max 100.0 %"
+	n := width - 6 - statsW
+	if n < 10 {
+		n = 10
+	}
+	samples := h.last(n)
+	if len(samples) < 2 {
+		return dimStyle.Render("(collecting history…)")
+	}
+	cpu := make([]float64, 0, len(samples))
+	mem := make([]float64, 0, len(samples))
+	load := make([]float64, 0, len(samples))
+	memTotal := 0.0
+	for _, s := range samples {
+		cpu = append(cpu, s.CPUPercent)
+		mem = append(mem, float64(s.MemUsedBytes))
+		load = append(load, s.Load1)
+		if t := float64(s.MemTotalBytes); t > memTotal {
+			memTotal = t
 		}
-		label := s.Label
-		if label == "" {
-			label = s.Key
+	}
+	row := func(name string, vals []float64, lo, hi float64, fmtv func(float64) string) string {
+		mn, avg, mx := minAvgMax(vals)
+		return padRight(name, 6) + sparkRange(vals, lo, hi) +
+			dimStyle.Render(fmt.Sprintf("  min %s avg %s max %s", fmtv(mn), fmtv(avg), fmtv(mx)))
+	}
+	_, _, loadMax := minAvgMax(load)
+	return strings.Join([]string{
+		row("CPU", cpu, 0, 100, func(v float64) string { return fmt.Sprintf("%.1f%%", v) }),
+		row("Mem", mem, 0, memTotal, func(v float64) string { return host.HumanBytes(uint64(v)) }),
+		row("Load", load, 0, loadMax, func(v float64) string { return fmt.Sprintf("%.2f", v) }),
+	}, "\n")
+}
+
+// sessionColumns marks the sorted column with an arrow; when the compact
+// overview doesn't show that column, the caller puts it in the title.
+func sessionColumns(wide bool, srt sessionSort) []column {
+	cols := []column{{title: "KEY", width: 28}, {title: "LABEL", width: 24}, {title: "MODEL", width: 16}, {title: "UPDATED", width: 8}}
+	if wide {
+		cols = append(cols, column{title: "PROVIDER", width: 14}, column{title: "IN", width: 9, right: true}, column{title: "OUT", width: 9, right: true}, column{title: "TOTAL", width: 10, right: true})
+	}
+	for i := range cols {
+		if cols[i].title == sessionSortColumns[srt.key] {
+			cols[i].title += srt.arrow()
 		}
-		lines = append(lines,
-			fmt.Sprintf("%s  %s  %s",
-				padRight(shortKey(s.Key), 28),
-				padRight(firstN(label, 24), 24),
-				padRight(modelShort(s.Model), 16),
-			)+dimStyle.Render("  "+relTime(s.UpdatedAt)),
-		)
 	}
-	if len(subs) > 0 {
-		lines = append(lines, dimStyle.Render("subagents:"))
-		for i, r := range subs {
-			if i >= 6 {
-				lines = append(lines, dimStyle.Render("…"))
-				break
-			}
-			lines = append(lines, fmt.Sprintf("%s  %s", padRight(firstN(r.Label, 20), 20), dimStyle.Render(relTime(r.CreatedAt))))
+	return cols
+}
+
+func sessionRows(sessions []openclaw.Session, wide bool) []tableRow {
+	rows := make([]tableRow, 0, len(sessions))
+	for _, s := range sessions {
+		r := tableRow{{text: shortKey(s.Key)}, {text: sessionLabel(s)}, {text: modelShort(s.Model)}, {text: relTime(s.UpdatedAt), style: dimStyle}}
+		if wide {
+			r = append(r, plainRow(s.Provider, fmt.Sprint(s.InputTokens), fmt.Sprint(s.OutputTokens), fmt.Sprint(s.TotalTokens))...)
 		}
+		rows = append(rows, r)
 	}
-	return strings.Join(lines, "\n")
+	return rows
 }
 
-func renderTasks(tasks []openclaw.Task, f taskFilters) string {
-	lines := []string{titleStyle.Render("Latest Tasks")}
-	flt := make([]openclaw.Task, 0, len(tasks))
-	for _, t := range tasks {
-		if f.levels != nil && !f.levels[t.Level] {
+func sessionDetail(s openclaw.Session) [][2]string {
+	return [][2]string{
+		{"Key", s.Key},
+		{"Label", s.Label},
+		{"Model", s.Model},
+		{"Provider", s.Provider},
+		{"Updated", absTime(s.UpdatedAt) + " (" + relTime(s.UpdatedAt) + ")"},
+		{"Tokens", fmt.Sprintf("in %d  out %d  total %d", s.InputTokens, s.OutputTokens, s.TotalTokens)},
+	}
+}
+
+// subagentColumns: the wide form adds status, model, runtime and the task
+// text for the full-screen view.
+func subagentColumns(wide bool) []column {
+	if !wide {
+		return []column{{title: "LABEL", width: 20}, {title: "CREATED", width: 8}}
+	}
+	return []column{{title: "LABEL", width: 20}, {title: "STATUS", width: 9}, {title: "MODEL", width: 16}, {title: "CREATED", width: 8}, {title: "RUNTIME", width: 8}, {title: "TASK"}}
+}
+
+func subagentRows(subs []openclaw.SubagentRun, wide bool) []tableRow {
+	rows := make([]tableRow, 0, len(subs))
+	for _, r := range subs {
+		if !wide {
+			rows = append(rows, tableRow{{text: r.Label}, {text: relTime(r.CreatedAt), style: dimStyle}})
 			continue
 		}
-		if f.sources != nil && !f.sources[t.Source] {
-			continue
+		status, st := r.Status(), dimStyle
+		if status == "running" {
+			status, st = sevActive.mark(status), sevActive.style()
 		}
-		flt = append(flt, t)
+		rows = append(rows, tableRow{
+			{text: r.Label},
+			{text: status, style: st},
+			{text: modelShort(r.Model)},
+			{text: relTime(r.CreatedAt), style: dimStyle},
+			{text: runDuration(r)},
+			{text: r.Task},
+		})
 	}
-	sort.Slice(flt, func(i, j int) bool { return flt[i].At.After(flt[j].At) })
-	if len(flt) == 0 {
-		lines = append(lines, dimStyle.Render("(no tasks match filters)"))
-		return strings.Join(lines, "\n")
-	}
-	for i, t := range flt {
-		if i >= 20 {
-			lines = append(lines, dimStyle.Render("…"))
-			break
+	return rows
+}
+
+func subagentDetail(r openclaw.SubagentRun) [][2]string {
+	opt := func(t *time.Time) string {
+		if t == nil {
+			return "-"
 		}
-		lvl := string(t.Level)
-		src := string(t.Source)
-		st := dimStyle
+		return absTime(*t)
+	}
+	return [][2]string{
+		{"Run", r.RunID},
+		{"Label", r.Label},
+		{"Status", r.Status()},
+		{"Model", r.Model},
+		{"Session", r.ChildSessionKey},
+		{"Created", absTime(r.CreatedAt)},
+		{"Started", opt(r.StartedAt)},
+		{"Finished", opt(r.FinishedAt)},
+		{"Runtime", runDuration(r)},
+		{"Task", r.Task},
+	}
+}
+
+func runDuration(r openclaw.SubagentRun) string {
+	if r.StartedAt == nil {
+		return "-"
+	}
+	end := time.Now()
+	if r.FinishedAt != nil {
+		end = *r.FinishedAt
+	}
+	return end.Sub(*r.StartedAt).Round(time.Second).String()
+}
+
+func taskColumns() []column {
+	return []column{{title: "TIME", width: 8}, {title: "LEVEL", width: 7}, {title: "SOURCE", width: 8}, {title: "TASK"}}
+}
+
+func taskRows(tasks []openclaw.Task) []tableRow {
+	rows := make([]tableRow, 0, len(tasks))
+	for _, t := range tasks {
+		level, st := string(t.Level), dimStyle
 		switch t.Level {
 		case openclaw.LevelError:
-			st = badStyle
+			level, st = sevBad.mark(level), sevBad.style()
+		case openclaw.LevelWarn:
+			level, st = sevWarn.mark(level), sevWarn.style()
 		case openclaw.LevelInfo:
 			st = okStyle
 		}
-		lines = append(lines, fmt.Sprintf("%s %s %s %s", dimStyle.Render(timeFmt(t.At)), st.Render(padRight(lvl, 5)), dimStyle.Render(padRight(src, 8)), firstN(t.Title+": "+t.Detail, 80)))
+		rows = append(rows, tableRow{
+			{text: timeFmt(t.At), style: dimStyle},
+			{text: level, style: st},
+			{text: string(t.Source), style: dimStyle},
+			{text: t.Title + ": " + firstLine(t.Detail)},
+		})
 	}
-	return strings.Join(lines, "\n")
+	return rows
 }
 
-func renderCrons(crons []openclaw.CronJob) string {
-	lines := []string{titleStyle.Render("Crons")}
-	if len(crons) == 0 {
-		lines = append(lines, dimStyle.Render("(no jobs.json)"))
-		return strings.Join(lines, "\n")
+func taskDetail(t openclaw.Task) [][2]string {
+	return [][2]string{
+		{"At", absTime(t.At)},
+		{"Level", string(t.Level)},
+		{"Source", string(t.Source)},
+		{"Title", t.Title},
+		{"Detail", t.Detail},
 	}
-	for i, c := range crons {
-		if i >= 12 {
-			lines = append(lines, dimStyle.Render("…"))
-			break
-		}
-		en := "on"
-		if !c.Enabled {
-			en = "off"
-		}
+}
+
+func cronColumns() []column {
+	return []column{{title: "NAME", width: 20}, {title: "EN", width: 3}, {title: "NEXT", width: 5}, {title: "LAST", width: 8}, {title: "STATUS", width: 8}, {title: "ERROR"}}
+}
+
+func cronRows(crons []openclaw.CronJob) []tableRow {
+	rows := make([]tableRow, 0, len(crons))
+	for _, c := range crons {
 		next := "-"
 		if c.NextRun != nil {
 			next = relTimeAbs(*c.NextRun)
@@ -182,31 +424,117 @@ func renderCrons(crons []openclaw.CronJob) string {
 		if c.LastRun != nil {
 			last = relTime(*c.LastRun)
 		}
-		status := c.LastStatus
+		sev := sevNone
+		switch c.LastStatus {
+		case "ok":
+			sev = sevOK
+		case "error":
+			sev = sevBad
+		}
 		st := dimStyle
-		if status == "ok" {
-			st = okStyle
+		if sev != sevNone {
+			st = sev.style()
 		}
-		if status == "error" {
-			st = badStyle
+		errText := firstLine(c.LastError)
+		if errText == "" {
+			errText = "-"
+		}
+		rows = append(rows, tableRow{
+			{text: c.Name},
+			{text: onOff(c.Enabled), style: dimStyle},
+			{text: next},
+			{text: last},
+			{text: sev.mark(orDash(c.LastStatus)), style: st},
+			{text: errText, style: st},
+		})
+	}
+	return rows
+}
+
+func cronDetail(c openclaw.CronJob) [][2]string {
+	opt := func(t *time.Time) string {
+		if t == nil {
+			return "-"
 		}
-		err := firstN(c.LastError, 40)
-		if err == "" {
-			err = "-"
+		return absTime(*t)
+	}
+	return [][2]string{
+		{"ID", c.ID},
+		{"Name", c.Name},
+		{"Enabled", onOff(c.Enabled)},
+		{"Schedule", c.Schedule},
+		{"TZ", orDash(c.TZ)},
+		{"Next run", opt(c.NextRun)},
+		{"Last run", opt(c.LastRun)},
+		{"Status", orDash(c.LastStatus)},
+		{"Error", orDash(c.LastError)},
+	}
+}
+
+// renderPanel draws a titled table in h lines (title included). With fewer
+// than three lines it collapses to the title and row count.
+func renderPanel(title string, t table, cols []column, rows []tableRow, empty string, w, h int, focused bool, s search) string {
+	ttl := titleStyle.Render(title)
+	if focused {
+		ttl = selectedStyle.Bold(true).Render(title)
+	}
+	if s.active() {
+		ttl += "  " + warnStyle.Render("/"+s.query)
+	}
+	if h < 3 {
+		// collapsed: no room for a header and a row
+		return ttl + dimStyle.Render(fmt.Sprintf(" (%d)", len(rows)))
+	}
+	if len(rows) == 0 {
+		if s.active() {
+			empty = "(no matches)"
 		}
-		lines = append(lines, fmt.Sprintf("%s %s next:%s last:%s err:%s",
-			padRight(firstN(c.Name, 20), 20),
-			dimStyle.Render(en),
-			next,
-			last,
-			st.Render(err),
-		))
+		return ttl + "\n" + dimStyle.Render(empty)
+	}
+	return ttl + scrollPos(t, len(rows)) + "\n" + t.render(cols, rows, w, h-1, focused, s.hl)
+}
+
+// renderDetail shows every field of the highlighted record, wrapping long
+// values under their label.
+func renderDetail(fields [][2]string, w, h int) string {
+	const labelW = 10
+	valStyle := lipgloss.NewStyle().Width(max(10, w-labelW))
+	lines := []string{titleStyle.Render("Detail")}
+	for _, f := range fields {
+		val := strings.Split(valStyle.Render(strings.TrimSpace(f[1])), "\n")
+		for i, v := range val {
+			lbl := ""
+			if i == 0 {
+				lbl = f[0]
+			}
+			lines = append(lines, dimStyle.Render(padRight(lbl, labelW))+v)
+		}
+	}
+	if len(lines) > h {
+		lines = append(lines[:h-1], dimStyle.Render("…"))
 	}
 	return strings.Join(lines, "\n")
 }
 
 func timeFmt(t time.Time) string { return t.Format("15:04:05") }
 
+func absTime(t time.Time) string { return t.Format("2006-01-02 15:04:05") }
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+func firstLine(s string) string {
+	s = strings.TrimSpace(strings.ReplaceAll(s, "\r\n", "\n"))
+	if idx := strings.IndexByte(s, '\n'); idx >= 0 {
+		return strings.TrimSpace(s[:idx])
+	}
+	return s
+}
+
 func relTimeAbs(t time.Time) string {
 	d := time.Until(t)
 	if d < 0 {
@@ -221,19 +549,7 @@ func relTimeAbs(t time.Time) string {
 	return fmt.Sprintf("%dh", int(d.Hours()))
 }
 
-func padRight(s string, w int) string {
-	if len(s) >= w {
-		return s
-	}
-	return s + strings.Repeat(" ", w-len(s))
-}
-
-func shortKey(k string) string {
-	if len(k) <= 28 {
-		return k
-	}
-	return k[:27] + "…"
-}
+func shortKey(k string) string { return truncate(k, 28) }
 
 func modelShort(m string) string {
 	if m == "" {
@@ -0,0 +1,69 @@
+package ui
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/cl4wb0rg/clawtop/internal/query"
+)
+
+// search is a per-panel filter typed at the / prompt, in the query
+// language of internal/query (bare words are substring searches).
+type search struct {
+	query string
+	f     query.Filter
+	// hl picks out the matched text in table cells
+	hl  *regexp.Regexp
+	err error
+}
+
+func newSearch(q string) search {
+	s := search{query: q}
+	s.f, s.err = query.Parse(q)
+	if s.err == nil {
+		s.hl = s.f.Highlight()
+	}
+	return s
+}
+
+func (s search) active() bool { return !s.f.Empty() }
+
+var matchStyle = lipgloss.NewStyle().Reverse(true)
+
+// highlight renders txt in st with every match of re picked out.
+func highlight(txt string, st lipgloss.Style, re *regexp.Regexp) string {
+	if re == nil {
+		return st.Render(txt)
+	}
+	locs := re.FindAllStringIndex(txt, -1)
+	if len(locs) == 0 {
+		return st.Render(txt)
+	}
+	var b strings.Builder
+	prev := 0
+	for _, l := range locs {
+		if l[0] == l[1] {
+			continue
+		}
+		if l[0] > prev {
+			b.WriteString(st.Render(txt[prev:l[0]]))
+		}
+		b.WriteString(matchStyle.Render(txt[l[0]:l[1]]))
+		prev = l[1]
+	}
+	if prev < len(txt) {
+		b.WriteString(st.Render(txt[prev:]))
+	}
+	return b.String()
+}
+
+// renderPrompt is the search line shown in place of the legend while typing.
+func renderPrompt(s search) string {
+	ln := "/" + s.query + "█"
+	if s.err != nil {
+		ln += "  " + sevBad.render(s.err.Error())
+	}
+	return ln + dimStyle.Render("  enter keep  esc clear  e.g. level:error since:6h key:~cron")
+}
@@ -0,0 +1,136 @@
+package ui
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/cl4wb0rg/clawtop/internal/openclaw"
+)
+
+func searchModel(t *testing.T) model {
+	t.Helper()
+	mm, err := New(Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := mm.(model)
+	m.width, m.height = 120, 40
+	now := time.Now()
+	for _, k := range []string{"agent:main:alpha", "agent:main:beta", "agent:main:gamma", "agent:ops:delta"} {
+		m.sessions = append(m.sessions, openclaw.Session{Key: k, UpdatedAt: now})
+	}
+	return m
+}
+
+func press(m model, msgs ...tea.KeyMsg) model {
+	for _, msg := range msgs {
+		mm, _ := m.Update(msg)
+		m = mm.(model)
+	}
+	return m
+}
+
+func runes(s string) tea.KeyMsg { return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)} }
+
+func TestSearchPrompt(t *testing.T) {
+	m := press(searchModel(t), runes("/"))
+	if !m.prompt || m.promptPanel != panelSessions {
+		t.Fatalf("prompt=%v panel=%d", m.prompt, m.promptPanel)
+	}
+	m = press(m, runes("c"), runes("afé"))
+	if q := m.searches[panelSessions].query; q != "café" {
+		t.Fatalf("typed %q", q)
+	}
+	// backspace takes off a whole rune, not a byte of it
+	m = press(m, tea.KeyMsg{Type: tea.KeyBackspace})
+	if q := m.searches[panelSessions].query; q != "caf" {
+		t.Fatalf("backspace left %q", q)
+	}
+	m = press(m, tea.KeyMsg{Type: tea.KeyCtrlU})
+	if q := m.searches[panelSessions].query; q != "" {
+		t.Fatalf("ctrl+u left %q", q)
+	}
+
+	// enter keeps the search, esc drops it
+	m = press(m, runes("main"), tea.KeyMsg{Type: tea.KeyEnter})
+	if m.prompt || !m.searches[panelSessions].active() || m.panelLen(panelSessions) != 3 {
+		t.Fatalf("enter: prompt=%v search=%+v rows=%d", m.prompt, m.searches[panelSessions], m.panelLen(panelSessions))
+	}
+	m = press(m, runes("/"), tea.KeyMsg{Type: tea.KeyEsc})
+	if m.prompt || m.searches[panelSessions].active() || m.panelLen(panelSessions) != 4 {
+		t.Fatalf("esc: prompt=%v search=%+v rows=%d", m.prompt, m.searches[panelSessions], m.panelLen(panelSessions))
+	}
+}
+
+func TestJumpMatch(t *testing.T) {
+	m := searchModel(t)
+	// no search: n and N leave the cursor alone
+	m = press(m, runes("n"))
+	if c := m.tables[panelSessions].cursor; c != 0 {
+		t.Fatalf("n without a search moved to %d", c)
+	}
+
+	m = press(m, runes("/"), runes("main"), tea.KeyMsg{Type: tea.KeyEnter})
+	for i, tc := range []struct {
+		key  string
+		want int
+	}{
+		// wraps past the first match to the last and back
+		{"N", 2},
+		{"n", 0},
+		{"n", 1},
+		{"n", 2},
+		{"n", 0},
+	} {
+		m = press(m, runes(tc.key))
+		if c := m.tables[panelSessions].cursor; c != tc.want {
+			t.Fatalf("step %d (%s): cursor %d, want %d", i, tc.key, c, tc.want)
+		}
+	}
+
+	// a search nothing matches leaves nothing to jump to
+	m = press(m, runes("/"), tea.KeyMsg{Type: tea.KeyCtrlU}, runes("nomatch"), tea.KeyMsg{Type: tea.KeyEnter}, runes("n"), runes("N"))
+	if c := m.tables[panelSessions].cursor; m.panelLen(panelSessions) != 0 || c != 0 {
+		t.Fatalf("empty panel: rows=%d cursor=%d", m.panelLen(panelSessions), c)
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	// mark matches in a way that survives a colourless terminal
+	saved := matchStyle
+	matchStyle = lipgloss.NewStyle().Transform(func(s string) string { return "[" + s + "]" })
+	defer func() { matchStyle = saved }()
+
+	for _, tc := range []struct {
+		query, in, want string
+	}{
+		{"sess", "my Session, sessions", "my [Sess]ion, [sess]ions"},
+		{"key:~s[0-9]+", "agent:s12:s3", "agent:[s12]:[s3]"},
+		{"nothing", "agent:main", "agent:main"},
+		// exclusions match rows, not text
+		{"-main", "agent:main", "agent:main"},
+	} {
+		s := newSearch(tc.query)
+		if s.err != nil {
+			t.Fatalf("%s: %v", tc.query, s.err)
+		}
+		if got := highlight(tc.in, lipgloss.NewStyle(), s.hl); got != tc.want {
+			t.Fatalf("%s: got %q, want %q", tc.query, got, tc.want)
+		}
+	}
+	if got := highlight("plain", lipgloss.NewStyle(), nil); got != "plain" {
+		t.Fatalf("nil regexp: %q", got)
+	}
+	// an empty match picks out nothing
+	if got := highlight("abc", lipgloss.NewStyle(), regexp.MustCompile("x*")); got != "abc" {
+		t.Fatalf("empty match: %q", got)
+	}
+
+	if s := newSearch(`"open`); s.err == nil || s.hl != nil || s.active() {
+		t.Fatalf("unterminated quote: %+v", s)
+	}
+}
@@ -0,0 +1,90 @@
+package ui
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/cl4wb0rg/clawtop/internal/openclaw"
+)
+
+type sessionSortKey int
+
+const (
+	sortUpdated sessionSortKey = iota
+	sortTotal
+	sortInput
+	sortOutput
+	sortModel
+	sortProvider
+	sortLabel
+	numSessionSorts
+)
+
+var sessionSortNames = [numSessionSorts]string{"updated", "total", "in", "out", "model", "provider", "label"}
+
+// sessionSortColumns maps each sort key to the column title it decorates.
+var sessionSortColumns = [numSessionSorts]string{"UPDATED", "TOTAL", "IN", "OUT", "MODEL", "PROVIDER", "LABEL"}
+
+func (k sessionSortKey) String() string { return sessionSortNames[k] }
+
+// sessionSort orders the sessions table. The zero value is the order
+// ReadSessionsJSON returns: most recently updated first.
+type sessionSort struct {
+	key sessionSortKey
+	asc bool
+}
+
+func (s sessionSort) arrow() string {
+	if s.asc {
+		return "▲"
+	}
+	return "▼"
+}
+
+// apply sorts sessions in place; ties keep the most recently updated first.
+func (s sessionSort) apply(sessions []openclaw.Session) {
+	compare := func(a, b openclaw.Session) int {
+		switch s.key {
+		case sortTotal:
+			return cmpInt(a.TotalTokens, b.TotalTokens)
+		case sortInput:
+			return cmpInt(a.InputTokens, b.InputTokens)
+		case sortOutput:
+			return cmpInt(a.OutputTokens, b.OutputTokens)
+		case sortModel:
+			return strings.Compare(strings.ToLower(a.Model), strings.ToLower(b.Model))
+		case sortProvider:
+			return strings.Compare(strings.ToLower(a.Provider), strings.ToLower(b.Provider))
+		case sortLabel:
+			return strings.Compare(strings.ToLower(sessionLabel(a)), strings.ToLower(sessionLabel(b)))
+		}
+		return a.UpdatedAt.Compare(b.UpdatedAt)
+	}
+	sort.SliceStable(sessions, func(i, j int) bool {
+		c := compare(sessions[i], sessions[j])
+		if c == 0 {
+			return sessions[i].UpdatedAt.After(sessions[j].UpdatedAt)
+		}
+		if s.asc {
+			return c < 0
+		}
+		return c > 0
+	})
+}
+
+func cmpInt(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func sessionLabel(s openclaw.Session) string {
+	if s.Label == "" {
+		return s.Key
+	}
+	return s.Label
+}
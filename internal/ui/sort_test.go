@@ -0,0 +1,39 @@
+package ui
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cl4wb0rg/clawtop/internal/openclaw"
+)
+
+func TestSessionSort(t *testing.T) {
+	now := time.Now()
+	ss := []openclaw.Session{
+		{Key: "a", Label: "beta", TotalTokens: 10, UpdatedAt: now},
+		{Key: "b", Label: "Alpha", TotalTokens: 30, UpdatedAt: now.Add(-time.Hour)},
+		{Key: "c", TotalTokens: 10, UpdatedAt: now.Add(time.Minute)},
+	}
+	keys := func() string {
+		out := ""
+		for _, s := range ss {
+			out += s.Key
+		}
+		return out
+	}
+
+	sessionSort{key: sortTotal}.apply(ss)
+	// ties fall back to most recently updated first
+	if got := keys(); got != "bca" {
+		t.Fatalf("total desc: %s", got)
+	}
+	sessionSort{key: sortLabel, asc: true}.apply(ss)
+	// an empty label sorts by key
+	if got := keys(); got != "bac" {
+		t.Fatalf("label asc: %s", got)
+	}
+	sessionSort{}.apply(ss)
+	if got := keys(); got != "cab" {
+		t.Fatalf("updated desc: %s", got)
+	}
+}
@@ -0,0 +1,95 @@
+package ui
+
+import (
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/cl4wb0rg/clawtop/internal/openclaw"
+	"github.com/cl4wb0rg/clawtop/internal/state"
+)
+
+var panelNames = [numPanels]string{"sessions", "subagents", "tasks", "crons"}
+
+// applyState restores a saved session over the defaults. Anything it does
+// not recognise (an old view name, a query that no longer parses) keeps
+// its default. Toggles and searches saved under another profile are left
+// to the active profile's filters.
+func (m *model) applyState(s state.State) {
+	for v := view(0); v < numViews; v++ {
+		if strings.EqualFold(s.View, viewNames[v]) {
+			m.view = v
+		}
+	}
+	for p, n := range panelNames {
+		if s.Focus == n {
+			m.focus = panel(p)
+		}
+	}
+	if d, err := time.ParseDuration(s.Refresh); err == nil && d >= 500*time.Millisecond {
+		m.refresh = d
+	}
+	for k, n := range sessionSortNames {
+		if s.SortKey == n {
+			m.sessionSort = sessionSort{key: sessionSortKey(k), asc: s.SortAsc}
+		}
+	}
+	if s.Profile != m.cfg.Profile {
+		return
+	}
+	for p, n := range panelNames {
+		if q := s.Searches[n]; q != "" {
+			if sr := newSearch(q); sr.err == nil {
+				m.searches[p] = sr
+			}
+		}
+	}
+	m.filter24h = s.Filter24h
+	m.hideRunSessions = s.HideRunSessions
+	m.primaryModelOnly = s.PrimaryModelOnly
+	for l, on := range s.Levels {
+		if _, ok := m.levels[openclaw.TaskLevel(l)]; ok {
+			m.levels[openclaw.TaskLevel(l)] = on
+		}
+	}
+	for src, on := range s.Sources {
+		if _, ok := m.sources[openclaw.TaskSource(src)]; ok {
+			m.sources[openclaw.TaskSource(src)] = on
+		}
+	}
+}
+
+// StateOf captures what a model returned by New would restore next run.
+func StateOf(tm tea.Model) (state.State, bool) {
+	m, ok := tm.(model)
+	if !ok {
+		return state.State{}, false
+	}
+	s := state.State{
+		View:             strings.ToLower(m.view.String()),
+		Focus:            panelNames[m.focus],
+		Refresh:          m.refresh.String(),
+		Profile:          m.cfg.Profile,
+		Filter24h:        m.filter24h,
+		HideRunSessions:  m.hideRunSessions,
+		PrimaryModelOnly: m.primaryModelOnly,
+		Levels:           map[string]bool{},
+		Sources:          map[string]bool{},
+		SortKey:          m.sessionSort.key.String(),
+		SortAsc:          m.sessionSort.asc,
+		Searches:         map[string]string{},
+	}
+	for l, on := range m.levels {
+		s.Levels[string(l)] = on
+	}
+	for src, on := range m.sources {
+		s.Sources[string(src)] = on
+	}
+	for p, sr := range m.searches {
+		if sr.query != "" {
+			s.Searches[panelNames[p]] = sr.query
+		}
+	}
+	return s, true
+}
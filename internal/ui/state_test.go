@@ -0,0 +1,76 @@
+package ui
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cl4wb0rg/clawtop/internal/config"
+	"github.com/cl4wb0rg/clawtop/internal/openclaw"
+)
+
+func TestStateRoundTrip(t *testing.T) {
+	mm, err := New(Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := mm.(model)
+	m.view = viewTasks
+	m.focus = panelCrons
+	m.refresh = 5 * time.Second
+	m.filter24h = false
+	m.levels[openclaw.LevelDebug] = true
+	m.sources[openclaw.SourceTool] = true
+	m.sessionSort = sessionSort{key: sortLabel, asc: true}
+	m.searches[panelTasks] = newSearch("level:error")
+
+	st, ok := StateOf(m)
+	if !ok {
+		t.Fatal("StateOf")
+	}
+	mm, err = New(Config{State: &st})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := mm.(model)
+	if got.view != viewTasks || got.focus != panelCrons || got.refresh != 5*time.Second ||
+		got.filter24h || !got.hideRunSessions || !got.levels[openclaw.LevelDebug] || !got.sources[openclaw.SourceTool] ||
+		got.sessionSort != m.sessionSort || got.searches[panelTasks].query != "level:error" {
+		st2, _ := StateOf(got)
+		t.Fatalf("restored %+v", st2)
+	}
+
+	// flags beat the saved state
+	mm, _ = New(Config{State: &st, Refresh: time.Second, Filter: "heartbeat"})
+	got = mm.(model)
+	if got.refresh != time.Second || got.searches[panelTasks].query != "heartbeat" {
+		t.Fatalf("refresh=%s search=%q", got.refresh, got.searches[panelTasks].query)
+	}
+}
+
+func TestStateOtherProfile(t *testing.T) {
+	mm, _ := New(Config{Profile: "staging"})
+	m := mm.(model)
+	m.view = viewCrons
+	m.hideRunSessions = false
+	m.levels[openclaw.LevelDebug] = true
+	m.searches[panelSessions] = newSearch("model:opus")
+	st, _ := StateOf(m)
+	if st.Profile != "staging" {
+		t.Fatalf("profile=%q", st.Profile)
+	}
+
+	// prod keeps its own filters and starts without staging's searches
+	prod := config.Default().Filters
+	prod.Levels = map[string]bool{"error": true}
+	mm, _ = New(Config{Profile: "prod", Filters: prod, State: &st})
+	got := mm.(model)
+	if got.view != viewCrons || !got.hideRunSessions || got.levels[openclaw.LevelDebug] || !got.levels[openclaw.LevelError] || got.searches[panelSessions].query != "" {
+		st2, _ := StateOf(got)
+		t.Fatalf("restored %+v", st2)
+	}
+
+	mm, _ = New(Config{Profile: "staging", State: &st})
+	if got := mm.(model); got.hideRunSessions || !got.levels[openclaw.LevelDebug] || got.searches[panelSessions].query != "model:opus" {
+		t.Fatal("staging's own state not restored")
+	}
+}
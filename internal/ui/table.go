@@ -0,0 +1,169 @@
+package ui
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// column is one table column. A zero width column takes whatever is left.
+type column struct {
+	title string
+	width int
+	right bool
+}
+
+type cell struct {
+	text  string
+	style lipgloss.Style
+}
+
+type tableRow []cell
+
+// plainRow builds a row of unstyled cells.
+func plainRow(texts ...string) tableRow {
+	r := make(tableRow, len(texts))
+	for i, t := range texts {
+		r[i] = cell{text: t}
+	}
+	return r
+}
+
+var (
+	headerStyle   = lipgloss.NewStyle().Bold(true).Underline(true)
+	selectedStyle = lipgloss.NewStyle().Reverse(true)
+)
+
+// table is the scroll state of one panel. Rows are rebuilt from the model
+// on every render, so only the cursor and offset live here.
+type table struct {
+	cursor int
+	offset int
+}
+
+// move shifts the cursor by delta over n rows, keeping it inside a window
+// of height rows.
+func (t *table) move(delta, n, height int) {
+	t.cursor += delta
+	t.clamp(n, height)
+}
+
+func (t *table) clamp(n, height int) {
+	if height < 1 {
+		height = 1
+	}
+	if t.cursor >= n {
+		t.cursor = n - 1
+	}
+	if t.cursor < 0 {
+		t.cursor = 0
+	}
+	if t.cursor < t.offset {
+		t.offset = t.cursor
+	}
+	if t.cursor >= t.offset+height {
+		t.offset = t.cursor - height + 1
+	}
+	if t.offset > n-height {
+		t.offset = n - height
+	}
+	if t.offset < 0 {
+		t.offset = 0
+	}
+}
+
+// render draws a header line and up to height-1 rows. The cursor row is
+// highlighted only when focused; matches of hl (if any) are picked out.
+func (t table) render(cols []column, rows []tableRow, width, height int, focused bool, hl *regexp.Regexp) string {
+	widths := columnWidths(cols, width)
+	hdr := make([]string, len(cols))
+	for i, c := range cols {
+		hdr[i] = fitCell(c.title, widths[i], c.right)
+	}
+	lines := []string{headerStyle.Render(strings.Join(hdr, "  "))}
+
+	page := height - 1
+	t.clamp(len(rows), page)
+	for i := t.offset; i < len(rows) && i < t.offset+page; i++ {
+		sel := focused && i == t.cursor
+		parts := make([]string, len(cols))
+		for j, c := range cols {
+			var cl cell
+			if j < len(rows[i]) {
+				cl = rows[i][j]
+			}
+			txt := fitCell(cl.text, widths[j], c.right)
+			if !sel {
+				txt = highlight(txt, cl.style, hl)
+			}
+			parts[j] = txt
+		}
+		ln := strings.Join(parts, "  ")
+		if sel {
+			ln = selectedStyle.Render(ln)
+		}
+		lines = append(lines, ln)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func columnWidths(cols []column, width int) []int {
+	out := make([]int, len(cols))
+	used := 2 * (len(cols) - 1)
+	flex := -1
+	for i, c := range cols {
+		if c.width == 0 {
+			flex = i
+			continue
+		}
+		out[i] = c.width
+		used += c.width
+	}
+	if flex >= 0 {
+		out[flex] = width - used
+		if out[flex] < 8 {
+			out[flex] = 8
+		}
+		used += out[flex]
+	}
+	// too narrow: take from the widest columns first
+	for used > width {
+		widest := 0
+		for i := range out {
+			if out[i] > out[widest] {
+				widest = i
+			}
+		}
+		if out[widest] <= 4 {
+			break
+		}
+		out[widest]--
+		used--
+	}
+	return out
+}
+
+func fitCell(s string, w int, right bool) string {
+	if w <= 0 {
+		return ""
+	}
+	s = truncate(strings.TrimSpace(strings.ReplaceAll(s, "\n", " ")), w)
+	if right {
+		return padLeft(s, w)
+	}
+	return padRight(s, w)
+}
+
+// scrollPos is the "3/40" shown next to a panel title.
+func scrollPos(t table, n int) string {
+	if n == 0 {
+		return ""
+	}
+	c := t.cursor
+	if c >= n {
+		c = n - 1
+	}
+	return dimStyle.Render(fmt.Sprintf(" %d/%d", c+1, n))
+}
@@ -0,0 +1,99 @@
+package ui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestTableMove(t *testing.T) {
+	for _, tc := range []struct {
+		name           string
+		start          table
+		delta, n, page int
+		cursor, offset int
+	}{
+		{"down inside the page", table{}, 1, 10, 3, 1, 0},
+		{"down past the page", table{cursor: 1}, 5, 10, 3, 6, 4},
+		{"past the bottom", table{cursor: 6, offset: 4}, 100, 10, 3, 9, 7},
+		{"past the top", table{cursor: 9, offset: 7}, -100, 10, 3, 0, 0},
+		{"up above the offset", table{cursor: 5, offset: 5}, -1, 10, 3, 4, 4},
+		{"page taller than the rows", table{}, 5, 3, 10, 2, 0},
+		{"no rows", table{cursor: 4, offset: 2}, 1, 0, 3, 0, 0},
+		{"no room", table{}, 5, 10, 0, 5, 5},
+	} {
+		tb := tc.start
+		tb.move(tc.delta, tc.n, tc.page)
+		if tb.cursor != tc.cursor || tb.offset != tc.offset {
+			t.Fatalf("%s: cursor=%d offset=%d, want %d %d", tc.name, tb.cursor, tb.offset, tc.cursor, tc.offset)
+		}
+	}
+}
+
+func TestTableClampShrunkRows(t *testing.T) {
+	// the rows under the cursor went away between refreshes
+	tb := table{cursor: 9, offset: 7}
+	tb.clamp(4, 3)
+	if tb.cursor != 3 || tb.offset != 1 {
+		t.Fatalf("cursor=%d offset=%d", tb.cursor, tb.offset)
+	}
+	// fewer rows than the page: nothing to scroll
+	tb.clamp(2, 3)
+	if tb.cursor != 1 || tb.offset != 0 {
+		t.Fatalf("cursor=%d offset=%d", tb.cursor, tb.offset)
+	}
+}
+
+func TestColumnWidths(t *testing.T) {
+	cols := []column{{width: 10}, {}, {width: 5}}
+	for _, tc := range []struct {
+		width int
+		want  []int
+	}{
+		// the flexible column takes what the others and the gaps leave
+		{40, []int{10, 21, 5}},
+		// it keeps 8 cells, and the widest columns give way first
+		{20, []int{5, 6, 5}},
+		// no column goes below 4, even if the table then overflows
+		{5, []int{4, 4, 4}},
+	} {
+		if got := columnWidths(cols, tc.width); !slices.Equal(got, tc.want) {
+			t.Fatalf("width %d: got %v, want %v", tc.width, got, tc.want)
+		}
+	}
+}
+
+func TestTableRender(t *testing.T) {
+	cols := []column{{title: "KEY", width: 6}, {title: "TOKENS", width: 6, right: true}}
+	var rows []tableRow
+	for i := 0; i < 5; i++ {
+		rows = append(rows, plainRow(fmt.Sprintf("row%d", i), fmt.Sprint(i*100)))
+	}
+	// a header and two rows; the cursor drags the window along
+	out := ansi.Strip(table{cursor: 3}.render(cols, rows, 14, 3, true, nil))
+	want := "KEY     TOKENS\nrow2       200\nrow3       300"
+	if out != want {
+		t.Fatalf("got\n%s\nwant\n%s", out, want)
+	}
+	// cells are cut to their column
+	out = ansi.Strip(table{}.render(cols, []tableRow{plainRow("a long key", "1234567")}, 14, 2, false, nil))
+	if ln := strings.Split(out, "\n")[1]; ln != "a lon…  12345…" {
+		t.Fatalf("row %q", ln)
+	}
+}
+
+func TestScrollPos(t *testing.T) {
+	if got := scrollPos(table{}, 0); got != "" {
+		t.Fatalf("empty: %q", got)
+	}
+	if got := ansi.Strip(scrollPos(table{cursor: 2}, 40)); got != " 3/40" {
+		t.Fatalf("got %q", got)
+	}
+	// a cursor left behind by shrinking rows shows the last one
+	if got := ansi.Strip(scrollPos(table{cursor: 9}, 4)); got != " 4/4" {
+		t.Fatalf("got %q", got)
+	}
+}
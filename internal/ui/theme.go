@@ -0,0 +1,111 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/cl4wb0rg/clawtop/internal/config"
+)
+
+// theme is the palette the package styles are built from. An empty colour
+// leaves the terminal's own foreground.
+type theme struct {
+	dim, ok, warn, bad lipgloss.Color
+	// bold marks ok/warn/bad in bold too, for themes that lean on weight
+	bold bool
+	// faint stands in for the dim colour when there are no colours
+	faint bool
+}
+
+// themes by name. deuteranopia avoids red/green pairs (blue is ok, orange
+// and yellow mark trouble); mono is what NO_COLOR gets.
+var themes = map[string]theme{
+	"dark":          {dim: "241", ok: "2", warn: "3", bad: "1"},
+	"light":         {dim: "243", ok: "28", warn: "130", bad: "124"},
+	"high-contrast": {ok: "10", warn: "11", bad: "9", bold: true},
+	"deuteranopia":  {dim: "244", ok: "33", warn: "220", bad: "208", bold: true},
+	"mono":          {bold: true, faint: true},
+}
+
+// DefaultTheme is used when neither flag nor config picks one.
+const DefaultTheme = "dark"
+
+// ThemeNames lists the valid theme names, sorted.
+func ThemeNames() []string {
+	out := make([]string, 0, len(themes))
+	for n := range themes {
+		out = append(out, n)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// applyTheme rebuilds the package styles from the named theme; an empty
+// name means DefaultTheme.
+func applyTheme(name string) error {
+	if name == "" {
+		name = DefaultTheme
+	}
+	th, ok := themes[name]
+	if !ok {
+		return fmt.Errorf("unknown theme %q (want one of %s)", name, strings.Join(ThemeNames(), ", "))
+	}
+	fg := func(c lipgloss.Color) lipgloss.Style {
+		st := lipgloss.NewStyle()
+		if c != "" {
+			st = st.Foreground(c)
+		}
+		return st
+	}
+	dimStyle = fg(th.dim).Faint(th.faint)
+	okStyle = fg(th.ok).Bold(th.bold)
+	warnStyle = fg(th.warn).Bold(th.bold)
+	badStyle = fg(th.bad).Bold(th.bold)
+	return nil
+}
+
+// severity is a status that gets a symbol as well as a colour, so it
+// reads the same without colour or with a colour-blind palette.
+type severity int
+
+const (
+	sevNone severity = iota
+	sevOK
+	sevActive
+	sevWarn
+	sevBad
+)
+
+var severityMarks = [...]string{sevNone: "", sevOK: "✓ ", sevActive: "▶ ", sevWarn: "! ", sevBad: "✗ "}
+
+func (s severity) style() lipgloss.Style {
+	switch s {
+	case sevOK, sevActive:
+		return okStyle
+	case sevWarn:
+		return warnStyle
+	case sevBad:
+		return badStyle
+	}
+	return lipgloss.NewStyle()
+}
+
+// mark prefixes txt with the severity's symbol.
+func (s severity) mark(txt string) string { return severityMarks[s] + txt }
+
+// render is mark in the severity's style.
+func (s severity) render(txt string) string { return s.style().Render(s.mark(txt)) }
+
+// thresh grades v against a warn/bad threshold.
+func thresh(v float64, t config.Threshold) severity {
+	switch {
+	case v >= t.Bad:
+		return sevBad
+	case v >= t.Warn:
+		return sevWarn
+	}
+	return sevNone
+}
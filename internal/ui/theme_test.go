@@ -0,0 +1,32 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cl4wb0rg/clawtop/internal/openclaw"
+)
+
+func TestApplyTheme(t *testing.T) {
+	defer applyTheme("")
+	for _, n := range append(ThemeNames(), "") {
+		if err := applyTheme(n); err != nil {
+			t.Fatalf("%q: %v", n, err)
+		}
+	}
+	if err := applyTheme("solarized"); err == nil || !strings.Contains(err.Error(), "deuteranopia") {
+		t.Fatalf("err=%v", err)
+	}
+}
+
+func TestStatusMarkers(t *testing.T) {
+	rows := cronRows([]openclaw.CronJob{{Name: "a", LastStatus: "ok"}, {Name: "b", LastStatus: "error"}, {Name: "c"}})
+	for i, want := range []string{"✓ ok", "✗ error", "-"} {
+		if got := rows[i][4].text; got != want {
+			t.Fatalf("row %d status=%q want %q", i, got, want)
+		}
+	}
+	if got := thresh(95, thresholds.Temp).mark("95°C"); got != "✗ 95°C" {
+		t.Fatalf("thresh mark=%q", got)
+	}
+}
@@ -0,0 +1,427 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/cl4wb0rg/clawtop/internal/collect"
+	"github.com/cl4wb0rg/clawtop/internal/host"
+	"github.com/cl4wb0rg/clawtop/internal/openclaw"
+	"github.com/cl4wb0rg/clawtop/internal/query"
+)
+
+// view is one full-screen page. Overview is the classic two-column screen;
+// the others give a single panel the whole terminal.
+type view int
+
+const (
+	viewOverview view = iota
+	viewSessions
+	viewSubagents
+	viewTasks
+	viewCrons
+	viewTokens
+	viewHost
+	viewProcesses
+	numViews
+)
+
+var viewNames = [numViews]string{"Overview", "Sessions", "Subagents", "Tasks", "Crons", "Tokens", "Host", "Processes"}
+
+func (v view) String() string { return viewNames[v] }
+
+var (
+	tabStyle       = lipgloss.NewStyle().Padding(0, 1)
+	activeTabStyle = lipgloss.NewStyle().Padding(0, 1).Reverse(true).Bold(true)
+)
+
+func renderTabs(active view, w int) string { return strings.Join(tabParts(active, w), "") }
+
+// tabParts renders one tab per view, naming every view, or only the active
+// one when the names do not fit in w.
+func tabParts(active view, w int) []string {
+	tabs := make([]string, 0, numViews)
+	short := make([]string, 0, numViews)
+	for v := view(0); v < numViews; v++ {
+		st := tabStyle
+		if v == active {
+			st = activeTabStyle
+		}
+		tabs = append(tabs, st.Render(fmt.Sprintf("F%d %s", v+1, v)))
+		if v == active {
+			short = append(short, tabs[v])
+		} else {
+			short = append(short, st.Render(fmt.Sprintf("F%d", v+1)))
+		}
+	}
+	if w == 0 || lipgloss.Width(strings.Join(tabs, "")) <= w {
+		return tabs
+	}
+	return short
+}
+
+// panel identifies a table-backed panel; each keeps its own cursor.
+type panel int
+
+const (
+	panelSessions panel = iota
+	panelSubagents
+	panelTasks
+	panelCrons
+	numPanels
+)
+
+var panelViews = [numPanels]view{viewSessions, viewSubagents, viewTasks, viewCrons}
+
+// activePanel is the panel that cursor keys drive: the view's own panel in
+// full-screen views, the focused one on the Overview.
+func (m model) activePanel() (panel, bool) {
+	if m.view == viewOverview {
+		return m.focus, true
+	}
+	for p, v := range panelViews {
+		if v == m.view {
+			return panel(p), true
+		}
+	}
+	return 0, false
+}
+
+// panelRows returns the table for p. wide adds the columns only the
+// full-screen views have room for. With several instances, sessions, tasks
+// and crons lead with the instance name.
+func (m model) panelRows(p panel, wide bool) (cols []column, rows []tableRow, empty string) {
+	var inst func(i int) string
+	switch p {
+	case panelSessions:
+		s := m.visibleSessions()
+		cols, rows, empty = sessionColumns(wide, m.sessionSort), sessionRows(s, wide), "(no sessions match filters)"
+		inst = func(i int) string { return s[i].Instance }
+	case panelSubagents:
+		return subagentColumns(wide), subagentRows(m.visibleSubagents(), wide), "(no runs.json)"
+	case panelTasks:
+		t := m.visibleTasks()
+		cols, rows, empty = taskColumns(), taskRows(t), "(no tasks match filters)"
+		inst = func(i int) string { return t[i].Instance }
+	case panelCrons:
+		c := m.visibleCrons()
+		cols, rows, empty = cronColumns(), cronRows(c), "(no jobs.json)"
+		inst = func(i int) string { return c[i].Instance }
+	}
+	if inst != nil && len(m.cfg.Instances) > 0 {
+		cols = append([]column{{title: "INST", width: m.instanceWidth()}}, cols...)
+		for i := range rows {
+			rows[i] = append(tableRow{{text: inst(i), style: dimStyle}}, rows[i]...)
+		}
+	}
+	return cols, rows, empty
+}
+
+// instanceWidth fits the longest instance name, within 4..12 cells.
+func (m model) instanceWidth() int {
+	w := 4
+	for _, in := range m.cfg.Instances {
+		w = max(w, textWidth(in.Name))
+	}
+	return min(w, 12)
+}
+
+func (m model) panelLen(p panel) int {
+	switch p {
+	case panelSessions:
+		return len(m.visibleSessions())
+	case panelSubagents:
+		return len(m.visibleSubagents())
+	case panelTasks:
+		return len(m.visibleTasks())
+	case panelCrons:
+		return len(m.visibleCrons())
+	}
+	return 0
+}
+
+// panelDetail returns the fields of row i of p for the detail pane.
+func (m model) panelDetail(p panel, i int) [][2]string {
+	var fields [][2]string
+	var inst string
+	switch p {
+	case panelSessions:
+		if s := m.visibleSessions(); i < len(s) {
+			fields, inst = sessionDetail(s[i]), s[i].Instance
+		}
+	case panelSubagents:
+		if r := m.visibleSubagents(); i < len(r) {
+			fields, inst = subagentDetail(r[i]), r[i].Instance
+		}
+	case panelTasks:
+		if t := m.visibleTasks(); i < len(t) {
+			fields, inst = taskDetail(t[i]), t[i].Instance
+		}
+	case panelCrons:
+		if c := m.visibleCrons(); i < len(c) {
+			fields, inst = cronDetail(c[i]), c[i].Instance
+		}
+	}
+	if fields != nil && len(m.cfg.Instances) > 0 {
+		fields = append([][2]string{{"Instance", inst}}, fields...)
+	}
+	return fields
+}
+
+func (m model) visibleSessions() []openclaw.Session {
+	out := filterBy(m.sessions, m.panelFilter(panelSessions), query.SessionRecord)
+	if m.sessionSort != (sessionSort{}) {
+		out = append([]openclaw.Session(nil), out...)
+		m.sessionSort.apply(out)
+	}
+	return out
+}
+
+func (m model) visibleSubagents() []openclaw.SubagentRun {
+	return filterBy(m.subagents, m.panelFilter(panelSubagents), query.SubagentRecord)
+}
+
+// visibleTasks are newest first, as collect.Read and collect.Merge leave
+// them; filterBy keeps that order and may return m.tasks itself.
+func (m model) visibleTasks() []openclaw.Task {
+	return filterBy(m.tasks, m.panelFilter(panelTasks), query.TaskRecord)
+}
+
+func (m model) visibleCrons() []openclaw.CronJob {
+	return filterBy(m.crons, m.panelFilter(panelCrons), query.CronRecord)
+}
+
+// panelFilter is the toggle filter for p combined with its search.
+func (m model) panelFilter(p panel) query.Filter {
+	return m.toggleFilter(p).And(m.searches[p].f)
+}
+
+// toggleFilter expresses the 1/2/3 and e/w/i/d, c/s/t toggles as a query.
+func (m model) toggleFilter(p panel) query.Filter {
+	t := query.Toggles{Window: m.cfg.Filters.Window, Recent: m.filter24h, HideRunSessions: m.hideRunSessions}
+	if m.primaryModelOnly {
+		t.PrimaryModel = m.primaryModel
+	}
+	for _, l := range []openclaw.TaskLevel{openclaw.LevelError, openclaw.LevelWarn, openclaw.LevelInfo, openclaw.LevelDebug} {
+		if m.levels[l] {
+			t.Levels = append(t.Levels, string(l))
+		}
+	}
+	for _, src := range []openclaw.TaskSource{openclaw.SourceCron, openclaw.SourceSubagent, openclaw.SourceTool} {
+		if m.sources[src] {
+			t.Sources = append(t.Sources, string(src))
+		}
+	}
+	switch p {
+	case panelSessions:
+		return t.Sessions()
+	case panelTasks:
+		return t.Tasks()
+	}
+	return query.Filter{}
+}
+
+// sortHint names the session sort in the overview title when the sorted
+// column is one only the full Sessions view shows.
+func (m model) sortHint() string {
+	for _, c := range sessionColumns(false, m.sessionSort) {
+		if strings.HasPrefix(c.title, sessionSortColumns[m.sessionSort.key]) {
+			return ""
+		}
+	}
+	return " (by " + m.sessionSort.key.String() + m.sessionSort.arrow() + ")"
+}
+
+// filterBy keeps the records that match f.
+func filterBy[T any](in []T, f query.Filter, rec func(T) query.Record) []T {
+	if f.Empty() {
+		return in
+	}
+	now := time.Now()
+	out := make([]T, 0, len(in))
+	for _, x := range in {
+		if f.Match(rec(x), now) {
+			out = append(out, x)
+		}
+	}
+	return out
+}
+
+// bodyHeight is the number of lines left for the active view; header,
+// tabs, filters and legend take one line each.
+func (m model) bodyHeight() int {
+	if m.height == 0 {
+		// no WindowSizeMsg yet
+		return 40
+	}
+	return max(1, m.height-4)
+}
+
+// fullLayout splits a full-screen panel view into table and detail heights.
+// Short terminals get no detail pane.
+func fullLayout(h int) (tableH, detailH int) {
+	if h < detailMinBody {
+		return h, 0
+	}
+	detailH = max(6, h/3)
+	return h - detailH - 1, detailH
+}
+
+// pageSize is how many data rows of p are visible at once.
+func (m model) pageSize(p panel) int {
+	if m.view != viewOverview {
+		th, _ := fullLayout(m.bodyHeight())
+		return max(1, th-2)
+	}
+	return max(1, m.overviewLayout(m.width, m.bodyHeight()).panels[p]-2)
+}
+
+// renderBody renders the active view into a w x h area.
+func (m model) renderBody(w, h int) string {
+	if p, ok := m.activePanel(); ok && m.view != viewOverview {
+		tableH, detailH := fullLayout(h)
+		cols, rows, empty := m.panelRows(p, true)
+		t := m.tables[p]
+		body := renderPanel(viewNames[m.view], t, cols, rows, empty, w, tableH, true, m.searches[p])
+		if detailH > 0 {
+			body += "\n\n" + renderDetail(m.panelDetail(p, t.cursor), w, detailH)
+		}
+		return body
+	}
+	switch m.view {
+	case viewTokens:
+		return renderTokensFull(m.tokenSamples, m.instances, w, h-1)
+	case viewHost:
+		return renderHost(m.host) + "\n\n" + renderHostHistory(m.hostHist, w) + "\n\n" + renderTemps(m.host.Sensors)
+	case viewProcesses:
+		return renderProcesses(m.procs, h-1)
+	}
+	return m.renderOverview(w, h)
+}
+
+func (m model) renderHostBlock(w int, compact bool) string {
+	if compact {
+		return renderHostLine(m.host)
+	}
+	return renderHost(m.host) + "\n" + renderHostHistory(m.hostHist, w)
+}
+
+func (m model) renderTokensBlock(compact bool) string {
+	if compact {
+		return renderTokensLine(m.tokenSamples) + renderInstancesLine(m.instances)
+	}
+	return renderTokens(m.tokenSamples) + renderInstances(m.instances)
+}
+
+// renderOverview lays the panels out per overviewLayout: two columns on
+// wide terminals, one stacked column on narrow ones.
+func (m model) renderOverview(w, h int) string {
+	l := m.overviewLayout(w, h)
+	pnl := func(p panel, title string, w int) string {
+		cols, rows, empty := m.panelRows(p, false)
+		return renderPanel(title, m.tables[p], cols, rows, empty, w, l.panels[p], m.focus == p, m.searches[p])
+	}
+	sep := strings.Repeat("\n", l.sep+1)
+	left := []string{
+		m.renderHostBlock(l.leftW, l.compact),
+		m.renderTokensBlock(l.compact),
+		pnl(panelSessions, "Sessions"+m.sortHint(), l.leftW),
+		pnl(panelSubagents, "Subagents", l.leftW),
+	}
+	right := []string{
+		pnl(panelTasks, "Latest Tasks", l.rightW),
+		pnl(panelCrons, "Crons", l.rightW),
+	}
+	if !l.twoCol {
+		return clipLines(strings.Join(append(left, right...), sep), w, h)
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top,
+		lipgloss.NewStyle().Width(l.leftW+1).Render(clipLines(strings.Join(left, sep), l.leftW, h)),
+		clipLines(strings.Join(right, sep), l.rightW, h))
+}
+
+// renderTokensFull shows a full-width sparkline, the per-instance totals
+// and the newest samples with per-sample deltas.
+func renderTokensFull(samples []openclaw.TokenSample, instances []collect.Data, w, rows int) string {
+	top := renderTokens(samples) + renderInstances(instances)
+	if len(samples) == 0 {
+		return top
+	}
+	lines := append(strings.Split(top, "\n"), "")
+	lines = append(lines, dimStyle.Render(fmt.Sprintf("%-19s  %14s  %10s  %10s", "time", "openclaw total", "delta", "claude $")))
+	for i := len(samples) - 1; i >= 0 && len(lines) < rows; i-- {
+		s := samples[i]
+		delta := "-"
+		if i > 0 {
+			delta = fmt.Sprintf("%+d", s.OpenClawTotal-samples[i-1].OpenClawTotal)
+		}
+		lines = append(lines, fmt.Sprintf("%-19s  %14d  %10s  %10.2f", s.At.Format("2006-01-02 15:04:05"), s.OpenClawTotal, delta, s.ClaudeCostUSD))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func renderTemps(s host.Sensors) string {
+	lines := []string{titleStyle.Render("Sensors")}
+	if len(s.Temps) == 0 {
+		lines = append(lines, dimStyle.Render("(no hwmon temperatures)"))
+	}
+	for _, t := range s.Temps {
+		sev := thresh(t.Celsius, tempThreshold(t))
+		crit := "-"
+		if t.CritC > 0 {
+			crit = fmt.Sprintf("%.0f°C", t.CritC)
+		}
+		lines = append(lines, fmt.Sprintf("%s  %s  %s  %s",
+			padRight(firstN(t.Chip, 12), 12),
+			padRight(firstN(t.Label, 20), 20),
+			sev.style().Render(padLeft(sev.mark(fmt.Sprintf("%.1f°C", t.Celsius)), 9)),
+			dimStyle.Render("crit "+crit),
+		))
+	}
+	if freq := renderFreq(s); len(freq) > 0 {
+		lines = append(lines, strings.Join(freq, "   "))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// renderProcesses lists processes by CPU, OpenClaw/Claude ones in bold.
+func renderProcesses(procs []host.Process, rows int) string {
+	lines := []string{titleStyle.Render("Processes")}
+	if len(procs) == 0 {
+		lines = append(lines, dimStyle.Render("(collecting…)"))
+		return strings.Join(lines, "\n")
+	}
+	sorted := append([]host.Process(nil), procs...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].CPUPercent != sorted[j].CPUPercent {
+			return sorted[i].CPUPercent > sorted[j].CPUPercent
+		}
+		return sorted[i].RSSBytes > sorted[j].RSSBytes
+	})
+	lines = append(lines, dimStyle.Render(fmt.Sprintf("%7s  %1s  %6s  %8s  %s", "PID", "S", "CPU%", "RSS", "COMMAND")))
+	for _, p := range sorted {
+		if len(lines) >= rows {
+			lines = append(lines, dimStyle.Render("…"))
+			break
+		}
+		cmd := p.Cmdline
+		if cmd == "" {
+			cmd = "[" + p.Comm + "]"
+		}
+		ln := fmt.Sprintf("%7d  %1s  %6.1f  %8s  %s", p.PID, p.State, p.CPUPercent, host.HumanBytes(p.RSSBytes), firstN(cmd, 100))
+		if isAgentProcess(p) {
+			ln = titleStyle.Render(ln)
+		}
+		lines = append(lines, ln)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func isAgentProcess(p host.Process) bool {
+	s := strings.ToLower(p.Comm + " " + p.Cmdline)
+	return strings.Contains(s, "openclaw") || strings.Contains(s, "claude")
+}
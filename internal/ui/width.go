@@ -0,0 +1,49 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/rivo/uniseg"
+)
+
+// textWidth is the number of terminal cells s occupies (two for wide runes).
+func textWidth(s string) int { return uniseg.StringWidth(s) }
+
+// truncate cuts s between grapheme clusters to at most w cells, ending in
+// "…" when anything was cut.
+func truncate(s string, w int) string {
+	if w <= 0 {
+		return ""
+	}
+	if textWidth(s) <= w {
+		return s
+	}
+	var b strings.Builder
+	used := 0
+	g := uniseg.NewGraphemes(s)
+	for g.Next() {
+		cw := g.Width()
+		if used+cw > w-1 {
+			break
+		}
+		b.WriteString(g.Str())
+		used += cw
+	}
+	return b.String() + "…"
+}
+
+// padRight pads s with spaces to w cells; wider strings are left alone.
+func padRight(s string, w int) string {
+	if n := textWidth(s); n < w {
+		return s + strings.Repeat(" ", w-n)
+	}
+	return s
+}
+
+// padLeft is padRight for right-aligned columns.
+func padLeft(s string, w int) string {
+	if n := textWidth(s); n < w {
+		return strings.Repeat(" ", w-n) + s
+	}
+	return s
+}
@@ -0,0 +1,39 @@
+package ui
+
+import (
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTruncateCells(t *testing.T) {
+	for _, tc := range []struct {
+		in   string
+		w    int
+		want string
+	}{
+		{"plain", 10, "plain"},
+		{"plain text", 6, "plain…"},
+		{"会话会话", 8, "会话会话"},
+		{"会话会话", 7, "会话会…"},
+		{"会话会话", 6, "会话…"},
+		// a ZWJ family and a flag are one cluster each and never split
+		{"a👨‍👩‍👧b", 3, "a…"},
+		{"🇩🇪🇩🇪", 4, "🇩🇪🇩🇪"},
+		{"🇩🇪🇩🇪", 3, "🇩🇪…"},
+	} {
+		got := truncate(tc.in, tc.w)
+		if got != tc.want || !utf8.ValidString(got) || textWidth(got) > tc.w {
+			t.Fatalf("truncate(%q, %d)=%q want %q", tc.in, tc.w, got, tc.want)
+		}
+	}
+}
+
+func TestFitCellAligns(t *testing.T) {
+	for _, s := range []string{"Main 🦀 会话", "Cron nightly", "日本語のラベルがとても長い"} {
+		for _, right := range []bool{false, true} {
+			if got := textWidth(fitCell(s, 12, right)); got != 12 {
+				t.Fatalf("fitCell(%q)=%d cells", s, got)
+			}
+		}
+	}
+}